@@ -0,0 +1,516 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/candiedyaml"
+	"github.com/cloudfoundry-incubator/cicerone/converters"
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+)
+
+//AnalysisSpec declares a timeline analysis: how to load entries, how to filter and group them,
+//which TimelinePoints make up the timeline and what to emit.
+//
+//Specs are loaded from YAML (.yml/.yaml) or JSON files, for example:
+//
+//	name: fezzik-tasks
+//	converter: lager
+//	filters:
+//	- source: "rep|executor|bbs"
+//	group-by: [task-guid, container-guid, guid, container.guid, allocation-request.Guid, handle]
+//	timeline:
+//	- {name: Desiring-Task, message: 'desire-task\.starting'}
+//	- {name: Allocating-Container, message: '\.allocating-container', match: after-previous}
+//	- {name: Resolved, message: 'resolved-task', squash: 0.5}
+//	attempts: true
+//	clock-skew:
+//	- {cause: {message: 'auction-runner\.requesting'}, effect: {source: rep, message: 'perform'}}
+//	output:
+//	  prefix: end-to-end
+//	  dt-stats: true
+//	  histograms: true
+//	  timelines: [start-time, end-time, vm]
+//	  vm-event-index: 1
+//	  concurrency: 1s
+//
+//When Attempts is set, a group whose first TimelinePoint matches several times (e.g. a retried task) yields one timeline per attempt.
+//
+//When Lineage is set, the group-by keys are different identifiers of the same object rather than alternative names for one identifier:
+//entries that mention several of them link them into a single correlation key (see Lineage).  A key may combine several data keys
+//with + (e.g. process-guid+index).
+type AnalysisSpec struct {
+	Name      string              `json:"name" yaml:"name"`
+	Converter string              `json:"converter" yaml:"converter"`
+	Filters   []MatcherSpec       `json:"filters" yaml:"filters"`
+	GroupBy   []string            `json:"group-by" yaml:"group-by"`
+	Timeline  []TimelinePointSpec `json:"timeline" yaml:"timeline"`
+	Attempts  bool                `json:"attempts" yaml:"attempts"`
+	Lineage   bool                `json:"lineage" yaml:"lineage"`
+	ClockSkew []CausalPairSpec    `json:"clock-skew" yaml:"clock-skew"`
+	Output    OutputSpec          `json:"output" yaml:"output"`
+}
+
+//MatcherSpec describes a Matcher.  Every non-empty field is interpreted as a regular expression
+//and all of them must match for the Matcher to match.  At least one field must be set: an empty MatcherSpec
+//(e.g. one whose only key is misspelled) is an error rather than a Matcher that matches every entry.
+//
+//Data maps DataGetter keys (e.g. `allocation-request.Guid`) to regular expressions.
+type MatcherSpec struct {
+	Source  string            `json:"source" yaml:"source"`
+	Message string            `json:"message" yaml:"message"`
+	Session string            `json:"session" yaml:"session"`
+	Job     string            `json:"job" yaml:"job"`
+	VM      string            `json:"vm" yaml:"vm"`
+	Data    map[string]string `json:"data" yaml:"data"`
+}
+
+//TimelinePointSpec describes a TimelinePoint.  Squash defaults to 1 when omitted.
+//
+//Match picks which matching entry to use: first (the default), last, nth (the Nth, 1-based) or after-previous
+//(the first one that does not precede the previous TimelinePoint's entry).  See MatchPolicy.
+//Optional points may be missing from a complete timeline.
+type TimelinePointSpec struct {
+	Name     string   `json:"name" yaml:"name"`
+	Squash   *float64 `json:"squash" yaml:"squash"`
+	Match    string   `json:"match" yaml:"match"`
+	N        int      `json:"n" yaml:"n"`
+	Optional bool     `json:"optional" yaml:"optional"`
+	MatcherSpec
+}
+
+//CausalPairSpec describes a pair of entries where the Cause always precedes the Effect (e.g. a request sent by one VM and handled by another).
+//Causes and effects are paired up by GroupBy, which defaults to the spec's group-by keys.
+//
+//When a spec lists causal pairs, the clock skew of each VM is estimated (see EstimateClockSkews) and corrected before timelines are constructed.
+type CausalPairSpec struct {
+	Cause   MatcherSpec `json:"cause" yaml:"cause"`
+	Effect  MatcherSpec `json:"effect" yaml:"effect"`
+	GroupBy []string    `json:"group-by" yaml:"group-by"`
+}
+
+//OutputSpec selects what an analysis emits.
+//
+//Timelines lists the orderings to plot timelines in: any of start-time, end-time and vm.
+//VMEventIndex picks the TimelinePoint used to determine a timeline's VM when sorting by vm.
+//HighlightPercentiles (e.g. [10, 90]) makes histograms and correlation plots highlight the fastest and slowest
+//pairs instead of the first and last 20% of the timelines.
+//Concurrency (e.g. 1s) plots the number of timelines in flight in each segment and the throughput at each TimelinePoint over time,
+//in buckets of the given width.
+//Failures prints the most common failure modes of the incomplete timelines (clustered by normalized error message)
+//and saves them as a CSV file.
+//CriticalPath (e.g. 10) compares how the elapsed time of the fastest and slowest 10% of the complete timelines
+//is spread over the TimelinePoints and ranks the TimelinePoints that dominate tail latency.
+//Windows computes DTStats per wall-clock window and plots latency percentiles over time (see WindowsSpec).
+//OpenMetrics exports the timelines as OpenMetrics text (see OpenMetricsSpec).
+//Swimlanes plots the timelines as a Gantt chart with one swimlane per VM.
+//HTML emits a self-contained, interactive HTML report.
+type OutputSpec struct {
+	Prefix               string           `json:"prefix" yaml:"prefix"`
+	CompleteOnly         bool             `json:"complete-only" yaml:"complete-only"`
+	DTStats              bool             `json:"dt-stats" yaml:"dt-stats"`
+	CSV                  bool             `json:"csv" yaml:"csv"`
+	Histograms           bool             `json:"histograms" yaml:"histograms"`
+	Correlation          bool             `json:"correlation" yaml:"correlation"`
+	HighlightPercentiles []float64        `json:"highlight-percentiles" yaml:"highlight-percentiles"`
+	Timelines            []string         `json:"timelines" yaml:"timelines"`
+	VMEventIndex         int              `json:"vm-event-index" yaml:"vm-event-index"`
+	Swimlanes            bool             `json:"swimlanes" yaml:"swimlanes"`
+	Concurrency          string           `json:"concurrency" yaml:"concurrency"`
+	Failures             bool             `json:"failures" yaml:"failures"`
+	CriticalPath         float64          `json:"critical-path" yaml:"critical-path"`
+	HTML                 bool             `json:"html" yaml:"html"`
+	OpenMetrics          *OpenMetricsSpec `json:"open-metrics" yaml:"open-metrics"`
+	Windows              *WindowsSpec     `json:"windows" yaml:"windows"`
+}
+
+//WindowsSpec slices an analysis into wall-clock windows
+//
+//Width (e.g. 5m) is the width of each window.  Windows start every Step (defaults to Width, i.e. fixed windows);
+//a Step smaller than Width yields sliding windows.  Percentiles defaults to 50, 90 and 99.
+type WindowsSpec struct {
+	Width       string    `json:"width" yaml:"width"`
+	Step        string    `json:"step" yaml:"step"`
+	Percentiles []float64 `json:"percentiles" yaml:"percentiles"`
+}
+
+//Windows returns the windows spanning the passed-in timelines
+func (w WindowsSpec) Windows(timelines Timelines) (Windows, error) {
+	width, step, err := w.widthAndStep()
+	if err != nil {
+		return nil, err
+	}
+	span := timelines.Span()
+	return SlidingWindows(span.Start, span.End, width, step), nil
+}
+
+func (w WindowsSpec) widthAndStep() (time.Duration, time.Duration, error) {
+	width, err := time.ParseDuration(w.Width)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid window width: %s", err.Error())
+	}
+	step := width
+	if w.Step != "" {
+		step, err = time.ParseDuration(w.Step)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid window step: %s", err.Error())
+		}
+	}
+	if width <= 0 || step <= 0 {
+		return 0, 0, fmt.Errorf("window width and step must be positive")
+	}
+	return width, step, nil
+}
+
+//OpenMetricsSpec configures the OpenMetrics export of an analysis
+//
+//The metrics are written to PREFIX-metrics.prom and every sample is labelled with analysis=PREFIX.
+//GroupBy is a getter query (e.g. vm, job or a data key) that is applied to the entry at GroupByPoint to split the timelines
+//into groups; each group's key becomes the value of the Label label (defaults to GroupBy).  Labels that clash with the labels
+//on individual samples (analysis, point, job, vm, index, le, quantile and state) are prefixed with group_, e.g. group-by: job yields group_job.
+//Timestamps stamps every sample with the time the last timeline ended, for backfilling a past run into Prometheus.
+type OpenMetricsSpec struct {
+	Namespace    string `json:"namespace" yaml:"namespace"`
+	GroupBy      string `json:"group-by" yaml:"group-by"`
+	GroupByPoint int    `json:"group-by-point" yaml:"group-by-point"`
+	Label        string `json:"label" yaml:"label"`
+	Timestamps   bool   `json:"timestamps" yaml:"timestamps"`
+}
+
+//OpenMetrics computes the metrics for the passed-in timelines
+func (o OpenMetricsSpec) OpenMetrics(analysis string, timelines Timelines) (*OpenMetrics, error) {
+	namespace := o.Namespace
+	if namespace == "" {
+		namespace = "cicerone"
+	}
+	metrics := NewOpenMetrics(namespace)
+	if o.Timestamps {
+		for _, timeline := range timelines {
+			if timeline.EndsAt().After(metrics.Timestamp) {
+				metrics.Timestamp = timeline.EndsAt()
+			}
+		}
+	}
+
+	labels := map[string]string{"analysis": analysis}
+	if o.GroupBy == "" {
+		metrics.AddTimelines(timelines, labels)
+		return metrics, nil
+	}
+
+	getter, err := ParseGetter(o.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	label := o.Label
+	if label == "" {
+		label = o.GroupBy
+	}
+	if label == "analysis" {
+		label = "group_analysis"
+	}
+	timelines.GroupBy(timelines.Description()[o.GroupByPoint].Matcher, getter).EachGroup(func(key interface{}, group Timelines) error {
+		labels[label] = fmt.Sprintf("%v", key)
+		metrics.AddTimelines(group, labels)
+		return nil
+	})
+	return metrics, nil
+}
+
+//LoadAnalysisSpec reads an AnalysisSpec from a YAML or JSON file (picked by extension) and validates it
+func LoadAnalysisSpec(path string) (AnalysisSpec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return AnalysisSpec{}, err
+	}
+
+	spec := AnalysisSpec{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		err = candiedyaml.Unmarshal(data, &spec)
+	default:
+		err = json.Unmarshal(data, &spec)
+	}
+	if err != nil {
+		return AnalysisSpec{}, fmt.Errorf("failed to parse %s: %s", path, err.Error())
+	}
+
+	return spec, spec.Validate()
+}
+
+//Validate ensures the spec is complete and that all its regular expressions compile
+func (s AnalysisSpec) Validate() error {
+	if _, ok := converters.LookupFormat(s.Converter); s.Converter != "" && !ok {
+		return fmt.Errorf("unknown converter: %s", s.Converter)
+	}
+	if len(s.GroupBy) == 0 {
+		return fmt.Errorf("spec must specify at least one group-by key")
+	}
+	for _, key := range s.GroupBy {
+		if strings.Contains(key, "+") && !s.Lineage {
+			return fmt.Errorf("group-by key %s combines several data keys, which requires lineage: true", key)
+		}
+	}
+	if len(s.Timeline) == 0 {
+		return fmt.Errorf("spec must specify at least one timeline point")
+	}
+	for i, filter := range s.Filters {
+		if _, err := filter.Matcher(); err != nil {
+			return fmt.Errorf("filter %d: %s", i, err.Error())
+		}
+	}
+	for i, point := range s.Timeline {
+		if point.Name == "" {
+			return fmt.Errorf("timeline point %d is missing a name", i)
+		}
+		if _, err := point.TimelinePoint(); err != nil {
+			return fmt.Errorf("timeline point %s: %s", point.Name, err.Error())
+		}
+	}
+	for i, pair := range s.ClockSkew {
+		if _, _, err := pair.Matchers(); err != nil {
+			return fmt.Errorf("clock-skew pair %d: %s", i, err.Error())
+		}
+	}
+	if s.Output.VMEventIndex < 0 || s.Output.VMEventIndex >= len(s.Timeline) {
+		return fmt.Errorf("vm-event-index %d is out of range", s.Output.VMEventIndex)
+	}
+	if len(s.Output.HighlightPercentiles) != 0 && len(s.Output.HighlightPercentiles) != 2 {
+		return fmt.Errorf("highlight-percentiles must have a lower and an upper percentile")
+	}
+	if p := s.Output.HighlightPercentiles; len(p) == 2 && !(0 <= p[0] && p[0] < p[1] && p[1] <= 100) {
+		return fmt.Errorf("highlight-percentiles must satisfy 0 <= lower < upper <= 100")
+	}
+	for _, ordering := range s.Output.Timelines {
+		if _, ok := timelineOrderings[ordering]; !ok {
+			return fmt.Errorf("unknown timeline ordering: %s", ordering)
+		}
+	}
+	if o := s.Output.OpenMetrics; o != nil {
+		if o.GroupByPoint < 0 || o.GroupByPoint >= len(s.Timeline) {
+			return fmt.Errorf("open-metrics group-by-point %d is out of range", o.GroupByPoint)
+		}
+		if o.GroupBy != "" {
+			if _, err := ParseGetter(o.GroupBy); err != nil {
+				return fmt.Errorf("open-metrics group-by: %s", err.Error())
+			}
+		}
+	}
+	if s.Output.CriticalPath < 0 || s.Output.CriticalPath > 50 {
+		return fmt.Errorf("critical-path quantile must be between 0 and 50")
+	}
+	if s.Output.Windows != nil {
+		if _, _, err := s.Output.Windows.widthAndStep(); err != nil {
+			return err
+		}
+	}
+	if s.Output.Concurrency != "" {
+		step, err := time.ParseDuration(s.Output.Concurrency)
+		if err != nil {
+			return fmt.Errorf("invalid concurrency bucket: %s", err.Error())
+		}
+		if step <= 0 {
+			return fmt.Errorf("concurrency bucket must be positive")
+		}
+	}
+	return nil
+}
+
+//format returns the converters format to load log files in: the global --format flag takes precedence over the spec's converter
+func (s AnalysisSpec) format() string {
+	if EntryFormat != "" {
+		return EntryFormat
+	}
+	return s.Converter
+}
+
+//LoadEntries streams the passed-in log file through the spec's converter, keeping only the entries that pass the spec's filters
+//
+//The converter names a converters format (e.g. lager, syslog).  The global --format flag takes precedence and the format is
+//detected when neither is given.
+func (s AnalysisSpec) LoadEntries(path string) (Entries, error) {
+	matcher, err := s.FilterMatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := converters.StreamFormat(path, s.format())
+	if err != nil {
+		return nil, err
+	}
+
+	return stream.Filter(And(EntryFilter, matcher)).Collect()
+}
+
+//FilterMatcher combines all the spec's filters into a single Matcher
+func (s AnalysisSpec) FilterMatcher() (Matcher, error) {
+	matchers := []Matcher{}
+	for _, filter := range s.Filters {
+		matcher, err := filter.Matcher()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return And(matchers...), nil
+}
+
+//Getter returns the DataGetter used to group entries
+func (s AnalysisSpec) Getter() Getter {
+	return DataGetter(s.GroupBy...)
+}
+
+//identifiers returns a Getter for each group-by key.  Keys that combine several data keys with + yield a CompositeGetter.
+func (s AnalysisSpec) identifiers() []Getter {
+	identifiers := []Getter{}
+	for _, key := range s.GroupBy {
+		getters := []Getter{}
+		for _, dataKey := range strings.Split(key, "+") {
+			getters = append(getters, DataGetter(dataKey))
+		}
+		if len(getters) == 1 {
+			identifiers = append(identifiers, getters[0])
+		} else {
+			identifiers = append(identifiers, CompositeGetter(getters...))
+		}
+	}
+	return identifiers
+}
+
+//GroupEntries groups the passed-in entries by the spec's keys.  If the spec asks for lineage, the keys are first resolved to
+//correlation keys learned from the entries.  If the spec asks for attempts each group is then split into attempts
+//(see GroupedEntries.SplitAttempts) and the keys are Attempts.
+func (s AnalysisSpec) GroupEntries(entries Entries) (*GroupedEntries, error) {
+	getter := s.Getter()
+	if s.Lineage {
+		getter = entries.Lineage(s.identifiers()...).Getter()
+	}
+	grouped := entries.GroupBy(getter)
+	if !s.Attempts {
+		return grouped, nil
+	}
+	description, err := s.TimelineDescription()
+	if err != nil {
+		return nil, err
+	}
+	return grouped.SplitAttempts(description[0].Matcher), nil
+}
+
+//EstimateClockSkews estimates the clock skew of each VM from the spec's causal pairs
+func (s AnalysisSpec) EstimateClockSkews(entries Entries) (ClockSkews, error) {
+	pairs := EntryPairs{}
+	for _, pair := range s.ClockSkew {
+		cause, effect, err := pair.Matchers()
+		if err != nil {
+			return nil, err
+		}
+
+		groupBy := pair.GroupBy
+		if len(groupBy) == 0 {
+			groupBy = s.GroupBy
+		}
+
+		pairs = append(pairs, entries.GroupBy(DataGetter(groupBy...)).CausalPairs(cause, effect)...)
+	}
+
+	return EstimateClockSkews(pairs), nil
+}
+
+//TimelineDescription constructs the TimelineDescription described by the spec
+func (s AnalysisSpec) TimelineDescription() (TimelineDescription, error) {
+	description := TimelineDescription{}
+	for _, point := range s.Timeline {
+		timelinePoint, err := point.TimelinePoint()
+		if err != nil {
+			return nil, err
+		}
+		description = append(description, timelinePoint)
+	}
+	return description, nil
+}
+
+//Matcher constructs the Matcher described by the spec
+func (m MatcherSpec) Matcher() (Matcher, error) {
+	regExps := map[string]string{
+		"source":  m.Source,
+		"message": m.Message,
+		"session": m.Session,
+		"job":     m.Job,
+		"vm":      m.VM,
+	}
+	getters := map[string]Getter{
+		"source":  GetSource,
+		"message": GetMessage,
+		"session": GetSession,
+		"job":     GetJob,
+		"vm":      GetVM,
+	}
+	for key, regExp := range m.Data {
+		regExps["data."+key] = regExp
+		getters["data."+key] = DataGetter(key)
+	}
+
+	matchers := []Matcher{}
+	for field, regExp := range regExps {
+		if regExp == "" {
+			continue
+		}
+		if _, err := regexp.Compile(regExp); err != nil {
+			return nil, fmt.Errorf("invalid %s regular expression: %s", field, err.Error())
+		}
+		matchers = append(matchers, RegExpMatcher(getters[field], regExp))
+	}
+	if len(matchers) == 0 {
+		return nil, fmt.Errorf("matcher must set at least one of source, message, session, job, vm or data")
+	}
+
+	return And(matchers...), nil
+}
+
+//Matchers constructs the cause and effect Matchers described by the spec
+func (p CausalPairSpec) Matchers() (Matcher, Matcher, error) {
+	cause, err := p.Cause.Matcher()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cause: %s", err.Error())
+	}
+	effect, err := p.Effect.Matcher()
+	if err != nil {
+		return nil, nil, fmt.Errorf("effect: %s", err.Error())
+	}
+	return cause, effect, nil
+}
+
+//TimelinePoint constructs the TimelinePoint described by the spec
+func (p TimelinePointSpec) TimelinePoint() (TimelinePoint, error) {
+	matcher, err := p.Matcher()
+	if err != nil {
+		return TimelinePoint{}, err
+	}
+
+	squash := 1.0
+	if p.Squash != nil {
+		squash = *p.Squash
+	}
+
+	policy, err := ParseMatchPolicy(p.Match)
+	if err != nil {
+		return TimelinePoint{}, err
+	}
+	if policy == NthMatch && p.N < 1 {
+		return TimelinePoint{}, fmt.Errorf("match nth requires n >= 1")
+	}
+
+	return TimelinePoint{
+		Name:     p.Name,
+		Matcher:  matcher,
+		Squash:   squash,
+		Policy:   policy,
+		N:        p.N,
+		Optional: p.Optional,
+	}, nil
+}
@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gonum/plot"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+	"github.com/cloudfoundry-incubator/cicerone/viz"
+	"github.com/onsi/say"
+)
+
+var timelineOrderings = map[string]func(Timelines, int){
+	"start-time": func(t Timelines, _ int) { t.SortByStartTime() },
+	"end-time":   func(t Timelines, _ int) { t.SortByEndTime() },
+	"vm":         func(t Timelines, index int) { t.SortByVMForEntryAtIndex(index) },
+}
+
+type Analyze struct{}
+
+func (a *Analyze) Usage() string {
+	return "analyze SPEC_FILE LOG_FILE"
+}
+
+func (a *Analyze) Description() string {
+	return `
+Runs the timeline analysis declared in SPEC_FILE (YAML or JSON) against LOG_FILE.
+
+The spec names the converter used to load LOG_FILE, filters to apply,
+the data keys to group entries by, the ordered timeline points and
+which plots and CSV files to emit.  See AnalysisSpec for the format.
+
+e.g. analyze ~/workspace/diego-release/perf/fezzik-tasks.yml ~/workspace/performance/10-cells/fezzik-40xtasks/optimization-4-better-logs.log
+`
+}
+
+func (a *Analyze) Command(outputDir string, args ...string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("Expected a spec file and a log file")
+	}
+
+	spec, err := LoadAnalysisSpec(args[0])
+	if err != nil {
+		return err
+	}
+
+	entries, err := spec.LoadEntries(args[1])
+	if err != nil {
+		return err
+	}
+
+	timelines, err := spec.ConstructTimelines(entries)
+	if err != nil {
+		return err
+	}
+
+	err = spec.Emit(timelines, outputDir)
+	if err != nil {
+		return err
+	}
+
+	if spec.Output.Failures {
+		return spec.EmitFailures(entries, timelines, outputDir)
+	}
+	return nil
+}
+
+//ConstructTimelines groups the passed-in entries by the spec's keys and constructs the spec's timelines
+//
+//If the spec lists causal pairs the entries are first corrected for clock skew and the estimated skews are printed.
+func (s AnalysisSpec) ConstructTimelines(entries Entries) (Timelines, error) {
+	description, err := s.TimelineDescription()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.ClockSkew) > 0 {
+		skews, err := s.EstimateClockSkews(entries)
+		if err != nil {
+			return nil, err
+		}
+		say.Println(0, say.Green("Estimated Clock Skew"))
+		fmt.Println(skews)
+		entries = skews.Correct(entries)
+	}
+
+	grouped, err := s.GroupEntries(entries)
+	if err != nil {
+		return nil, err
+	}
+	return grouped.ConstructTimelines(description)
+}
+
+//Emit prints and plots the passed-in Timelines as requested by the spec's output section
+func (s AnalysisSpec) Emit(timelines Timelines, outputDir string) error {
+	completeTimelines := timelines.CompleteTimelines()
+	say.Println(0, say.Red("Complete Timelines: %d/%d (%.2f%%)\n",
+		len(completeTimelines),
+		len(timelines),
+		float64(len(completeTimelines))/float64(len(timelines))*100.0))
+
+	if s.Output.CompleteOnly {
+		timelines = completeTimelines
+	}
+	if len(timelines) == 0 {
+		return fmt.Errorf("no timelines to emit")
+	}
+
+	prefix := s.prefix()
+
+	if s.Output.DTStats {
+		fmt.Println(timelines.DTStatsSlice())
+	}
+
+	if s.Output.DTStats && s.Attempts {
+		timelines.GroupByAttempt().EachGroup(func(number interface{}, attempts Timelines) error {
+			say.Println(0, say.Green("DTStats for Attempt %d (%d timelines)", number, len(attempts)))
+			fmt.Println(attempts.DTStatsSlice())
+			return nil
+		})
+	}
+
+	if s.Output.CSV {
+		f, err := os.Create(filepath.Join(outputDir, prefix+"-timelines.csv"))
+		if err != nil {
+			return err
+		}
+		timelines.ToCSV(f)
+		f.Close()
+	}
+
+	percentiles := s.Output.HighlightPercentiles
+
+	if s.Output.Histograms {
+		var histograms *viz.UniformBoard
+		if len(percentiles) == 2 {
+			histograms = viz.NewEntryPairsQuantileHistogramBoard(timelines, percentiles[0], percentiles[1])
+		} else {
+			histograms = viz.NewEntryPairsHistogramBoard(timelines)
+		}
+		err := histograms.Save(3.0*float64(len(timelines.Description())), 6.0, filepath.Join(outputDir, prefix+"-histograms.svg"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.Output.Correlation {
+		var correlationBoard *viz.UniformBoard
+		var err error
+		if len(percentiles) == 2 {
+			correlationBoard, err = viz.NewQuantileCorrelationBoard(timelines, percentiles[0], percentiles[1])
+		} else {
+			correlationBoard, err = viz.NewCorrelationBoard(timelines)
+		}
+		if err != nil {
+			return err
+		}
+		err = correlationBoard.Save(24.0, 24.0, filepath.Join(outputDir, prefix+"-correlation.svg"))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ordering := range s.Output.Timelines {
+		timelineOrderings[ordering](timelines, s.Output.VMEventIndex)
+		timelineBoard := &viz.Board{}
+		p, _ := plot.New()
+		p.Title.Text = "Timelines by " + ordering
+		p.Add(viz.NewTimelinesPlotter(timelines, timelines.StartsAfter().Seconds(), timelines.EndsAfter().Seconds()))
+		timelineBoard.AddSubPlot(p, viz.Rect{0, 0, 1.0, 1.0})
+		err := timelineBoard.Save(16.0, 10.0, filepath.Join(outputDir, prefix+"-timelines-by-"+ordering+".svg"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.Output.Swimlanes {
+		swimlanes, height := viz.NewSwimlanesBoard("Timelines by VM", timelines)
+		err := swimlanes.Save(16.0, height, filepath.Join(outputDir, prefix+"-swimlanes.svg"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.Output.Concurrency != "" {
+		step, _ := time.ParseDuration(s.Output.Concurrency)
+		concurrency := viz.NewConcurrencyBoard(timelines, step)
+		err := concurrency.Save(16.0, 10.0, filepath.Join(outputDir, prefix+"-concurrency.svg"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.Output.CriticalPath > 0 {
+		report := timelines.CriticalPathReport(s.Output.CriticalPath)
+		say.Println(0, say.Green("Critical Path"))
+		fmt.Println(report)
+		criticalPath := viz.NewCriticalPathBoard(report)
+		err := criticalPath.Save(3.0*float64(len(report.Summaries))+4.0, 10.0, filepath.Join(outputDir, prefix+"-critical-path.svg"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.Output.Windows != nil {
+		windows, err := s.Output.Windows.Windows(timelines)
+		if err != nil {
+			return err
+		}
+		say.Println(0, say.Green("DTStats by Window"))
+		fmt.Println(timelines.DTStatsByWindow(windows))
+		latencies := viz.NewLatencyPercentilesBoard(timelines, windows, s.Output.Windows.Percentiles)
+		err = latencies.Save(16.0, 3.0*float64(len(timelines.Description())), filepath.Join(outputDir, prefix+"-latency-over-time.svg"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.Output.OpenMetrics != nil {
+		metrics, err := s.Output.OpenMetrics.OpenMetrics(prefix, timelines)
+		if err != nil {
+			return err
+		}
+		f, err := os.Create(filepath.Join(outputDir, prefix+"-metrics.prom"))
+		if err != nil {
+			return err
+		}
+		err = metrics.Write(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.Output.HTML {
+		report, err := viz.NewHTMLReport(prefix, timelines)
+		if err != nil {
+			return err
+		}
+		err = report.Save(filepath.Join(outputDir, prefix+"-report.html"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//EmitFailures prints a report of the failure modes of the incomplete timelines and saves it as a CSV file
+//
+//The passed-in entries must be the entries the timelines were constructed from: they are used to find the errors
+//logged under each timeline's grouping key.
+func (s AnalysisSpec) EmitFailures(entries Entries, timelines Timelines, outputDir string) error {
+	grouped, err := s.GroupEntries(entries)
+	if err != nil {
+		return err
+	}
+	modes := grouped.Failures(timelines).Modes()
+	if len(modes) == 0 {
+		say.Println(0, say.Green("No Failures"))
+		return nil
+	}
+
+	say.Println(0, say.Red("Failure Modes"))
+	fmt.Println(modes)
+
+	f, err := os.Create(filepath.Join(outputDir, s.prefix()+"-failures.csv"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return modes.ToCSV(f)
+}
+
+func (s AnalysisSpec) prefix() string {
+	if s.Output.Prefix != "" {
+		return s.Output.Prefix
+	}
+	if s.Name != "" {
+		return s.Name
+	}
+	return "analysis"
+}
@@ -3,23 +3,17 @@ package commands
 import (
 	"fmt"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/gonum/plot"
 
-	"github.com/cloudfoundry-incubator/cicerone/converters"
 	. "github.com/cloudfoundry-incubator/cicerone/dsl"
 	"github.com/cloudfoundry-incubator/cicerone/viz"
 	"github.com/onsi/say"
 	"github.com/pivotal-golang/lager"
 )
 
-var appGuidRegExp *regexp.Regexp
-
-func init() {
-	appGuidRegExp = regexp.MustCompile(`([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
-}
+var appGuidGetter = MessageRegExpGetter(`([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
 
 type AnalyzeCFPushes struct{}
 
@@ -55,17 +49,17 @@ func (f *AnalyzeCFPushes) Command(outputDir string, args ...string) error {
 	}
 
 	timelineDescription := TimelineDescription{
-		// {"Created", MatchMessage(`Updated app with guid .* \(\{"diego"=>true`), 1},
-		{"CC-Says-Start", MatchMessage(`Updated app with guid .* \(\{"state"=>"STARTED"\}\)`), 1},
-		{"Creating-Stg", And(MatchMessage(`Creating container`), MatchJob("STG")), 1},
-		{"Created-Stg", And(MatchMessage(`Successfully created container`), MatchJob("STG")), 1},
-		{"Finish-DL-App", MatchMessage(`Downloaded app package`), 1},
-		{"Finish-DL-Buildpack", MatchMessage(`Downloaded buildpacks`), 1},
-		{"Finish-Builder", MatchMessage(`Staging complete`), 1},
-		{"Finish-Upload", MatchMessage(`Uploading complete`), 1},
-		{"Creating-Inst", And(MatchMessage(`Creating container`), MatchJob("CELL"), MatchIndex(0)), 1},
-		{"Created-Inst", And(MatchMessage(`Successfully created container`), MatchJob("CELL"), MatchIndex(0)), 1},
-		{"Healthy", MatchMessage(`healthcheck passed`), 1},
+		// {Name: "Created", Matcher: MatchMessage(`Updated app with guid .* \(\{"diego"=>true`), Squash: 1},
+		{Name: "CC-Says-Start", Matcher: MatchMessage(`Updated app with guid .* \(\{"state"=>"STARTED"\}\)`), Squash: 1},
+		{Name: "Creating-Stg", Matcher: And(MatchMessage(`Creating container`), MatchJob("STG")), Squash: 1},
+		{Name: "Created-Stg", Matcher: And(MatchMessage(`Successfully created container`), MatchJob("STG")), Squash: 1},
+		{Name: "Finish-DL-App", Matcher: MatchMessage(`Downloaded app package`), Squash: 1},
+		{Name: "Finish-DL-Buildpack", Matcher: MatchMessage(`Downloaded buildpacks`), Squash: 1},
+		{Name: "Finish-Builder", Matcher: MatchMessage(`Staging complete`), Squash: 1},
+		{Name: "Finish-Upload", Matcher: MatchMessage(`Uploading complete`), Squash: 1},
+		{Name: "Creating-Inst", Matcher: And(MatchMessage(`Creating container`), MatchJob("CELL"), MatchIndex(0)), Squash: 1},
+		{Name: "Created-Inst", Matcher: And(MatchMessage(`Successfully created container`), MatchJob("CELL"), MatchIndex(0)), Squash: 1},
+		{Name: "Healthy", Matcher: MatchMessage(`healthcheck passed`), Squash: 1},
 	}
 
 	timelines, err := byApplication.ConstructTimelines(timelineDescription)
@@ -95,7 +89,7 @@ func (f *AnalyzeCFPushes) Command(outputDir string, args ...string) error {
 func loadCFPushFiles(files ...string) (*GroupedEntries, error) {
 	groups := NewGroupedEntries()
 	for _, file := range files {
-		entries, err := converters.EntriesFromLoggregatorLogs(file)
+		entries, err := loadEntries(file)
 		if err != nil {
 			return nil, err
 		}
@@ -123,12 +117,12 @@ func getApplicationGuid(e Entries) (string, bool) {
 		return "", false
 	}
 
-	matches := appGuidRegExp.FindStringSubmatch(entry.Message)
-	if matches == nil {
+	guid, ok := appGuidGetter.Get(entry)
+	if !ok {
 		return "", false
 	}
 
-	return matches[1], true
+	return guid.(string), true
 }
 
 func plotCFPushesTimelinesAndHistograms(timelines Timelines, outputDir string, prefix string) {
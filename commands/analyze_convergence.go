@@ -6,7 +6,6 @@ import (
 
 	"github.com/gonum/plot"
 
-	"github.com/cloudfoundry-incubator/cicerone/converters"
 	. "github.com/cloudfoundry-incubator/cicerone/dsl"
 	"github.com/cloudfoundry-incubator/cicerone/viz"
 	"github.com/onsi/say"
@@ -35,20 +34,21 @@ func (f *AnalyzeConvergenceForMissingCells) Command(outputDir string, args ...st
 		return fmt.Errorf("Expected a log file and a session")
 	}
 
-	entries, err := converters.EntriesFromLagerFile(args[0])
+	stream, err := streamEntries(args[0])
 	if err != nil {
 		return err
 	}
 
-	entries = entries.Filter(MatchSession(`^` + args[1] + `\.`))
-
-	byLRP := entries.GroupBy(DataGetter("process-guid"))
+	byLRP, err := stream.Filter(MatchSession(`^` + args[1] + `\.`)).GroupBy(DataGetter("process-guid"))
+	if err != nil {
+		return err
+	}
 
 	timelineDescription := TimelineDescription{
-		{"Noticed-Missing-Cell", MatchMessage(`converge-lrps.calculate-convergence.missing-cell`), 1},
-		{"Removing-Actual-LRP", MatchMessage(`start-missing-actual.remove-actual-lrp.starting`), 1},
-		{"Removed-Actual-LRP", MatchMessage(`start-missing-actual.remove-actual-lrp.succeeded`), 1},
-		{"Adding-Start-Auction", MatchMessage(`start-missing-actual.adding-start-auction`), 1},
+		{Name: "Noticed-Missing-Cell", Matcher: MatchMessage(`converge-lrps.calculate-convergence.missing-cell`), Squash: 1},
+		{Name: "Removing-Actual-LRP", Matcher: MatchMessage(`start-missing-actual.remove-actual-lrp.starting`), Squash: 1},
+		{Name: "Removed-Actual-LRP", Matcher: MatchMessage(`start-missing-actual.remove-actual-lrp.succeeded`), Squash: 1},
+		{Name: "Adding-Start-Auction", Matcher: MatchMessage(`start-missing-actual.adding-start-auction`), Squash: 1},
 	}
 
 	timelines, err := byLRP.ConstructTimelines(timelineDescription)
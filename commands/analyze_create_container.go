@@ -3,22 +3,14 @@ package commands
 import (
 	"fmt"
 	"path/filepath"
-	"regexp"
 
 	"github.com/gonum/plot"
 
-	"strings"
-
-	"github.com/cloudfoundry-incubator/cicerone/converters"
 	. "github.com/cloudfoundry-incubator/cicerone/dsl"
 	"github.com/cloudfoundry-incubator/cicerone/viz"
 	"github.com/onsi/say"
 )
 
-func init() {
-	appGuidRegExp = regexp.MustCompile(`([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
-}
-
 type AnalyzeCreateContainer struct{}
 
 func (f *AnalyzeCreateContainer) Usage() string {
@@ -41,22 +33,22 @@ func (f *AnalyzeCreateContainer) Command(outputDir string, args ...string) error
 		return fmt.Errorf("Expected a garden log file")
 	}
 
-	entriesByHandle, err := loadGardenLogFiles(args[0])
-	if err != nil {
-		return err
+	timelineDescription := TimelineDescription{
+		{Name: "Creating", Matcher: MatchMessage(`garden-linux\.pool\..*\.creating`), Squash: 1},
+		{Name: "AcquiredPR", Matcher: MatchMessage(`garden-linux\.pool\..*\.acquired-pool-resources`), Squash: 1},
+		{Name: "rootfs-created", Matcher: MatchMessage(`garden-linux\.pool\..*\.create-rootfs\.command\.succeeded`), Squash: 1},
+		{Name: "create-sh-finished", Matcher: MatchMessage(`garden-linux\.pool\..*\.create-script\.command\.succeeded`), Squash: 1},
+		{Name: "log-chain-created", Matcher: MatchMessage(`garden-linux\..*\.filter\.log-chain-created`), Squash: 1},
+		{Name: "log-chain-conntrack-set-up", Matcher: MatchMessage(`garden-linux\..*\.filter\.log-chain-conntrack-set-up`), Squash: 1},
+		{Name: "log-chain-setup-finished", Matcher: MatchMessage(`garden-linux\..*\.filter\.log-chain-setup-finished`), Squash: 1},
+		{Name: "filter-setup", Matcher: MatchMessage(`garden-linux\.pool\..*\.setup-filter\.finished`), Squash: 1},
+		{Name: "Created", Matcher: MatchMessage(`garden-linux\.pool\..*\.created`), Squash: 1},
+		{Name: "Started", Matcher: MatchMessage(`garden-linux\.pool\..*\.start\.started`), Squash: 1},
 	}
 
-	timelineDescription := TimelineDescription{
-		{"Creating", MatchMessage(`garden-linux\.pool\..*\.creating`), 1},
-		{"AcquiredPR", MatchMessage(`garden-linux\.pool\..*\.acquired-pool-resources`), 1},
-		{"rootfs-created", MatchMessage(`garden-linux\.pool\..*\.create-rootfs\.command\.succeeded`), 1},
-		{"create-sh-finished", MatchMessage(`garden-linux\.pool\..*\.create-script\.command\.succeeded`), 1},
-		{"log-chain-created", MatchMessage(`garden-linux\..*\.filter\.log-chain-created`), 1},
-		{"log-chain-conntrack-set-up", MatchMessage(`garden-linux\..*\.filter\.log-chain-conntrack-set-up`), 1},
-		{"log-chain-setup-finished", MatchMessage(`garden-linux\..*\.filter\.log-chain-setup-finished`), 1},
-		{"filter-setup", MatchMessage(`garden-linux\.pool\..*\.setup-filter\.finished`), 1},
-		{"Created", MatchMessage(`garden-linux\.pool\..*\.created`), 1},
-		{"Started", MatchMessage(`garden-linux\.pool\..*\.start\.started`), 1},
+	entriesByHandle, err := loadGardenLogFiles(args[0], timelineDescription[0].Matcher)
+	if err != nil {
+		return err
 	}
 
 	timelines, err := entriesByHandle.ConstructTimelines(timelineDescription)
@@ -74,52 +66,27 @@ func (f *AnalyzeCreateContainer) Command(outputDir string, args ...string) error
 	return nil
 }
 
-func loadGardenLogFiles(file string) (*GroupedEntries, error) {
-	groups := NewGroupedEntries()
-	entries, err := converters.EntriesFromLagerFile(file)
+//gardenHandleGetter extracts the container handle garden embeds in its messages: e.g. garden-linux.pool.HANDLE.creating or garden-linux.HANDLE.filter.log-chain-created
+//(the identifier should really be data in the lager.Data hash)
+var gardenHandleGetter = MessageRegExpGetter(`^garden-linux\.(?:pool\.)?([^.]+)\.`)
+
+func loadGardenLogFiles(file string, creating Matcher) (*GroupedEntries, error) {
+	entries, err := loadEntries(file)
 	if err != nil {
 		return nil, err
 	}
 
-	//all of this is necessary only because garden injects the identifier in the message.  instead the identifier should be data in the lager.Data hash.
-	uniqueHandles := []string{}
-	for _, entry := range entries {
-		message := entry.LogEntry.Message
-		if strings.Contains(message, "garden-linux.pool") && strings.Contains(message, "creating") {
-			handle := getContainerHandle(message)
-			if !contains(uniqueHandles, handle) {
-				uniqueHandles = append(uniqueHandles, handle)
-			}
-		}
-	}
-
-	for _, handle := range uniqueHandles {
-		entriesForHandle := []Entry{}
-		for _, entry := range entries {
-			message := entry.LogEntry.Message
-			if strings.Contains(message, handle) {
-				entriesForHandle = append(entriesForHandle, entry)
-			}
+	groups := NewGroupedEntries()
+	entries.GroupBy(gardenHandleGetter).EachGroup(func(handle interface{}, entries Entries) error {
+		if _, found := entries.First(creating); found {
+			groups.AppendEntries(handle, entries)
 		}
-		groups.AppendEntries(handle, entriesForHandle)
-	}
+		return nil
+	})
 
 	return groups, nil
 }
 
-func getContainerHandle(message string) string {
-	return strings.Split(message, ".")[2]
-}
-
-func contains(list []string, element string) bool {
-	for _, e := range list {
-		if e == element {
-			return true
-		}
-	}
-	return false
-}
-
 func plotCreateContainerTimelinesAndHistograms(timelines Timelines, outputDir string, prefix string) {
 	timelines.SortByStartTime()
 
@@ -34,40 +34,50 @@ func (f *AnalyzeCellPerformance) Command(outputDir string, args ...string) error
 		return fmt.Errorf("Expected a rep log file and some timestamps")
 	}
 
-	entries, err := converters.EntriesFromLagerFile(args[0])
-	if err != nil {
-		return err
-	}
-
 	afterUnix, _ := strconv.Atoi(args[1])
 	beforeUnix, _ := strconv.Atoi(args[2])
 
 	after := time.Unix(int64(afterUnix), 0)
 	before := time.Unix(int64(beforeUnix), 0)
 
+	var entries Entries
+	var err error
+	if (EntryFormat == "" || EntryFormat == "cache") && converters.IsCacheFile(args[0]) {
+		//cache files let us seek straight to the rep's entries in the window
+		entries, err = converters.EntriesFromCacheFile(args[0], converters.CacheQuery{MinTime: after, MaxTime: before, Sources: []string{"rep"}})
+		if err == nil {
+			entries = entries.Filter(EntryFilter)
+		}
+	} else {
+		entries, err = loadEntries(args[0])
+	}
+	if err != nil {
+		return err
+	}
+
 	bySession := entries.Filter(MatchSource("rep")).Filter(MatchBetween(after, before)).GroupBy(GetSession)
 
 	fmt.Printf("Found %d log entries\n", len(entries))
 	fmt.Printf("Found %d sessions\n", len(bySession.Keys))
 
 	bulkCycleTimelineDescription := TimelineDescription{
-		{"Starting", MatchMessage(`sync\.starting`), 1},
-		{"Finished", MatchMessage(`sync\.finished`), 1},
+		{Name: "Starting", Matcher: MatchMessage(`sync\.starting`), Squash: 1},
+		{Name: "Finished", Matcher: MatchMessage(`sync\.finished`), Squash: 1},
 	}
 
 	auctionFetchingTimelineDescription := TimelineDescription{
-		{"StartFetching", MatchMessage(`rep.auction-fetch-state.handling`), 1},
-		{"FinishedFetching", MatchMessage(`rep.auction-fetch-state.success`), 1},
+		{Name: "StartFetching", Matcher: MatchMessage(`rep.auction-fetch-state.handling`), Squash: 1},
+		{Name: "FinishedFetching", Matcher: MatchMessage(`rep.auction-fetch-state.success`), Squash: 1},
 	}
 
 	auctionPerformingTimelineDescription := TimelineDescription{
-		{"StartPerforming", MatchMessage(`rep.auction-perform-work.handling`), 1},
-		{"FinishedPerforming", MatchMessage(`rep.auction-perform-work.success`), 1},
+		{Name: "StartPerforming", Matcher: MatchMessage(`rep.auction-perform-work.handling`), Squash: 1},
+		{Name: "FinishedPerforming", Matcher: MatchMessage(`rep.auction-perform-work.success`), Squash: 1},
 	}
 
 	containerMetricTimelineDescription := TimelineDescription{
-		{"StartFetching", MatchMessage(`rep.container-metrics-reporter.tick.started`), 1},
-		{"FinishedFetching", MatchMessage(`rep.container-metrics-reporter.tick.done`), 1},
+		{Name: "StartFetching", Matcher: MatchMessage(`rep.container-metrics-reporter.tick.started`), Squash: 1},
+		{Name: "FinishedFetching", Matcher: MatchMessage(`rep.container-metrics-reporter.tick.done`), Squash: 1},
 	}
 
 	fmt.Printf("Average Bulk Sync Duration: %v\n", calculateAverageTime(bulkCycleTimelineDescription, bySession))
@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+	"github.com/cloudfoundry-incubator/cicerone/viz"
+	"github.com/onsi/say"
+)
+
+//significanceLevel is the p-value below which a shift between runs is flagged
+const significanceLevel = 0.05
+
+type Compare struct{}
+
+func (c *Compare) Usage() string {
+	return "compare SPEC_FILE BASELINE_LOG_FILE LOG_FILE..."
+}
+
+func (c *Compare) Description() string {
+	return `
+Runs the timeline analysis declared in SPEC_FILE (see analyze) against
+two or more log files and compares every run to the first (baseline) run.
+
+For each timeline point compare prints the median, mean and p95 of every
+run, the change relative to the baseline and the p-value of a Mann-Whitney
+U test.  Shifts with p < 0.05 are flagged.  Overlaid histograms (one color
+per run) are saved to OUTPUT_DIR.
+
+e.g. compare fezzik-tasks.yml ~/workspace/performance/10-cells/fezzik-40xtasks/unoptimized.log ~/workspace/performance/10-cells/fezzik-40xtasks/optimization-1-no-logs.log
+`
+}
+
+func (c *Compare) Command(outputDir string, args ...string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("Expected a spec file and at least two log files")
+	}
+
+	spec, err := LoadAnalysisSpec(args[0])
+	if err != nil {
+		return err
+	}
+
+	labels := runLabels(args[1:])
+	runs := NewGroupedTimelines()
+	for i, path := range args[1:] {
+		entries, err := spec.LoadEntries(path)
+		if err != nil {
+			return err
+		}
+
+		timelines, err := spec.ConstructTimelines(entries)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err.Error())
+		}
+		if spec.Output.CompleteOnly {
+			timelines = timelines.CompleteTimelines()
+		}
+		if len(timelines) == 0 {
+			return fmt.Errorf("%s: no timelines found", path)
+		}
+
+		runs.AppendTimelines(labels[i], timelines)
+	}
+
+	printRunComparison(runs)
+
+	prefix := spec.Output.Prefix
+	if prefix == "" {
+		prefix = spec.Name
+	}
+	if prefix == "" {
+		prefix = "compare"
+	}
+
+	histograms := viz.NewGroupedTimelineEntryPairsHistogramBoard(runs)
+	return histograms.Save(3.0*float64(len(runs.Description())), 3.0, filepath.Join(outputDir, prefix+"-comparison-histograms.svg"))
+}
+
+//runLabels labels each run with its position and the shortest path suffix (without extension) that tells the runs apart,
+//e.g. run1/unified.log and run2/unified.log become "1:run1/unified" and "2:run2/unified".
+//The position keeps the labels unique even when the same path is passed twice.
+func runLabels(paths []string) []string {
+	components := [][]string{}
+	longest := 0
+	for _, path := range paths {
+		path = filepath.ToSlash(filepath.Clean(path))
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+		parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+		components = append(components, parts)
+		if len(parts) > longest {
+			longest = len(parts)
+		}
+	}
+
+	suffixes := make([]string, len(paths))
+	for n := 1; n <= longest; n++ {
+		seen := map[string]bool{}
+		unique := true
+		for i, parts := range components {
+			start := len(parts) - n
+			if start < 0 {
+				start = 0
+			}
+			suffixes[i] = strings.Join(parts[start:], "/")
+			if seen[suffixes[i]] {
+				unique = false
+			}
+			seen[suffixes[i]] = true
+		}
+		if unique {
+			break
+		}
+	}
+
+	labels := []string{}
+	for i, suffix := range suffixes {
+		labels = append(labels, fmt.Sprintf("%d:%s", i+1, suffix))
+	}
+	return labels
+}
+
+func printRunComparison(runs *GroupedTimelines) {
+	baseline := runs.Timelines[0]
+	for i, timelinePoint := range runs.Description() {
+		say.Println(0, say.Green(timelinePoint.Name))
+
+		baselineStats := baseline.EntryPairs(i).DTStats()
+		say.Println(1, "%s [%d] median: %s mean: %s p95: %s", runs.Keys[0], baselineStats.N, baselineStats.Median, baselineStats.Mean, baselineStats.P95)
+
+		for j := 1; j < len(runs.Keys); j++ {
+			stats := runs.Timelines[j].EntryPairs(i).DTStats()
+			_, _, p := baselineStats.Durations.MannWhitney(stats.Durations)
+
+			s := fmt.Sprintf("%s [%d] median: %s (%s) mean: %s (%s) p95: %s (%s) p=%.4f",
+				runs.Keys[j], stats.N,
+				stats.Median, durationDelta(baselineStats.Median, stats.Median),
+				stats.Mean, durationDelta(baselineStats.Mean, stats.Mean),
+				stats.P95, durationDelta(baselineStats.P95, stats.P95),
+				p)
+
+			switch {
+			case p >= significanceLevel:
+				say.Println(1, s)
+			case stats.Median > baselineStats.Median:
+				say.Println(1, say.Red("%s SLOWER", s))
+			default:
+				say.Println(1, say.Green("%s FASTER", s))
+			}
+		}
+	}
+}
+
+func durationDelta(baseline time.Duration, value time.Duration) string {
+	delta := value - baseline
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+		delta = -delta
+	}
+	if baseline == 0 {
+		return fmt.Sprintf("%s%s", sign, delta)
+	}
+	return fmt.Sprintf("%s%s, %s%.1f%%", sign, delta, sign, float64(delta)/float64(baseline)*100.0)
+}
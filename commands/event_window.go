@@ -0,0 +1,326 @@
+package commands
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/cicerone/converters"
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+	"github.com/onsi/say"
+)
+
+//defaultEventWindowBefore and defaultEventWindowAfter bound the window analyzed around each event
+const defaultEventWindowBefore = 10 * time.Second
+const defaultEventWindowAfter = 120 * time.Second
+
+var eventFileNameRegExp = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type event struct {
+	Name      string
+	Timestamp time.Time
+}
+
+func (e event) String() string {
+	return fmt.Sprintf("%s - %s", e.Name, e.Timestamp.Format("15:04:05.000"))
+}
+
+type EventWindows struct{}
+
+func (e *EventWindows) Usage() string {
+	return "event-windows SPEC_FILE LOG_PATH EVENTS [BEFORE [AFTER]]"
+}
+
+func (e *EventWindows) Description() string {
+	return `
+Runs the timeline analysis declared in SPEC_FILE (see analyze) in a window
+around each of a number of events and prints a summary table comparing the
+events.
+
+EVENTS is either:
+
+  - a CSV file of NAME,TIMESTAMP rows.  Timestamps are unix timestamps
+    (fractional seconds are allowed) or RFC 3339 times.  A header row is
+    skipped.
+  - an anchor query (see --filter) that finds the events in LOG_PATH, e.g.
+    'message =~ "cell-disappeared.converge-lrps.starting-convergence"'.
+    Anchors that occur within AFTER of the previous event are folded into
+    that event.
+
+Each window spans BEFORE (default 10s) before the event through AFTER
+(default 120s) after it.  LOG_PATH can be a BOSH tree or tarball (only the
+log lines in each window are slurped) or anything else converters can load.
+
+The spec's output section is emitted for every event, with the event name
+appended to the prefix (characters other than letters, digits, '.', '_'
+and '-' are replaced with '_').  The summary table is also saved to
+OUTPUT_DIR/PREFIX-event-windows.csv.  If any event fails to produce its
+output the remaining events are still analyzed and the command fails at
+the end.
+
+e.g. event-windows convergence.yml ~/workspace/performance/10-cells/cf-pushes/optimization-2-no-disk-quota/bosh-logs events.csv
+     event-windows convergence.yml unified.log 'message =~ "converge-lrps.starting-convergence"' 10s 2m
+`
+}
+
+func (e *EventWindows) Command(outputDir string, args ...string) error {
+	if len(args) < 3 || len(args) > 5 {
+		return fmt.Errorf("Expected a spec file, a log path, events and, optionally, the window before and after each event")
+	}
+
+	spec, err := LoadAnalysisSpec(args[0])
+	if err != nil {
+		return err
+	}
+
+	before, after := defaultEventWindowBefore, defaultEventWindowAfter
+	if len(args) > 3 {
+		before, err = time.ParseDuration(args[3])
+		if err != nil {
+			return err
+		}
+	}
+	if len(args) > 4 {
+		after, err = time.ParseDuration(args[4])
+		if err != nil {
+			return err
+		}
+	}
+
+	events, err := e.findEvents(spec, args[1], args[2], after)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("no events found")
+	}
+
+	windows := Windows{}
+	for _, event := range events {
+		windows = append(windows, Window{event.Timestamp.Add(-before), event.Timestamp.Add(after)})
+	}
+
+	say.Println(0, say.Green("Loading Entries"))
+	entries, err := e.loadWindows(spec, args[1], windows)
+	if err != nil {
+		return err
+	}
+
+	prefix := spec.prefix()
+	summaries := [][]string{}
+	failures := []string{}
+	for i, event := range events {
+		say.Println(0, say.Green("%s: %d entries", event, len(entries[i])))
+
+		timelines, err := e.emitEvent(spec, prefix, event, entries[i], outputDir)
+		if err != nil {
+			say.Println(1, say.Red(err.Error()))
+			failures = append(failures, fmt.Sprintf("%s: %s", event.Name, err.Error()))
+		}
+
+		summaries = append(summaries, eventSummary(event, entries[i], timelines, spec))
+	}
+
+	header := []string{"event", "time", "entries", "complete"}
+	for _, point := range spec.Timeline {
+		header = append(header, point.Name+" median")
+	}
+
+	say.Println(0, say.Green("Summary"))
+	say.Println(1, strings.Join(header, "\t"))
+	for _, summary := range summaries {
+		say.Println(1, strings.Join(summary, "\t"))
+	}
+
+	f, err := os.Create(filepath.Join(outputDir, prefix+"-event-windows.csv"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	csvWriter := csv.NewWriter(f)
+	csvWriter.Write(header)
+	csvWriter.WriteAll(summaries)
+	if err := csvWriter.Error(); err != nil {
+		return err
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d events failed:\n%s", len(failures), len(events), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+//emitEvent constructs the timelines for a single event and emits the spec's output for them.  The output files are prefixed
+//with the event name (sanitized so that it can be used in a file name).
+func (e *EventWindows) emitEvent(spec AnalysisSpec, prefix string, event event, entries Entries, outputDir string) (Timelines, error) {
+	timelines, err := spec.ConstructTimelines(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	eventSpec := spec
+	eventSpec.Output.Prefix = prefix + "-" + eventFileNameRegExp.ReplaceAllString(event.Name, "_")
+	err = eventSpec.Emit(timelines, outputDir)
+	if err != nil {
+		return timelines, err
+	}
+	if eventSpec.Output.Failures {
+		err = eventSpec.EmitFailures(entries, timelines, outputDir)
+		if err != nil {
+			return timelines, err
+		}
+	}
+	return timelines, nil
+}
+
+func eventSummary(event event, entries Entries, timelines Timelines, spec AnalysisSpec) []string {
+	summary := []string{event.Name, event.Timestamp.Format(time.RFC3339Nano), strconv.Itoa(len(entries))}
+	if len(timelines) == 0 {
+		summary = append(summary, "0/0")
+		for len(summary) < 4+len(spec.Timeline) {
+			summary = append(summary, "-")
+		}
+		return summary
+	}
+
+	complete := 0
+	for _, timeline := range timelines {
+		if timeline.IsComplete() {
+			complete++
+		}
+	}
+	summary = append(summary, fmt.Sprintf("%d/%d", complete, len(timelines)))
+	for _, stats := range timelines.DTStatsSlice() {
+		if stats.N == 0 {
+			summary = append(summary, "-")
+		} else {
+			summary = append(summary, stats.Median.String())
+		}
+	}
+	return summary
+}
+
+//findEvents reads events from a CSV file or, if eventsArg isn't a file, uses it as an anchor query to find the events in path
+func (e *EventWindows) findEvents(spec AnalysisSpec, path string, eventsArg string, after time.Duration) ([]event, error) {
+	if _, err := os.Stat(eventsArg); err == nil {
+		return readEventsCSV(eventsArg)
+	}
+
+	anchor, err := ParseMatcher(eventsArg)
+	if err != nil {
+		return nil, fmt.Errorf("%s is neither an events file nor a valid anchor query: %s", eventsArg, err.Error())
+	}
+
+	say.Println(0, say.Green("Finding Events"))
+	stream, err := converters.StreamFormat(path, spec.format())
+	if err != nil {
+		return nil, err
+	}
+	anchors, err := stream.Filter(And(EntryFilter, anchor)).Collect()
+	if err != nil {
+		return nil, err
+	}
+
+	events := []event{}
+	for _, entry := range anchors {
+		if len(events) > 0 && entry.Timestamp.Sub(events[len(events)-1].Timestamp) < after {
+			continue
+		}
+		events = append(events, event{
+			Name:      fmt.Sprintf("event-%02d", len(events)+1),
+			Timestamp: entry.Timestamp,
+		})
+	}
+	return events, nil
+}
+
+func readEventsCSV(path string) ([]event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	events := []event{}
+	for i, record := range records {
+		timestamp, err := parseEventTimestamp(record[1])
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("%s line %d: %s", path, i+1, err.Error())
+		}
+		events = append(events, event{
+			Name:      record[0],
+			Timestamp: timestamp,
+		})
+	}
+	return events, nil
+}
+
+func parseEventTimestamp(timestamp string) (time.Time, error) {
+	seconds, err := strconv.ParseFloat(timestamp, 64)
+	if err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %s: expected a unix timestamp or an RFC 3339 time", timestamp)
+	}
+	return t, nil
+}
+
+//loadWindows returns the entries (that pass the spec's filters) in each window
+//
+//BOSH trees are slurped window by window; anything else is read once.
+func (e *EventWindows) loadWindows(spec AnalysisSpec, path string, windows Windows) ([]Entries, error) {
+	matcher, err := spec.FilterMatcher()
+	if err != nil {
+		return nil, err
+	}
+	matcher = And(EntryFilter, matcher)
+
+	entries := make([]Entries, len(windows))
+	format := spec.format()
+	if format == "bosh" || (format == "" && converters.IsBOSHTree(path)) {
+		for i, window := range windows {
+			stream, err := converters.StreamEntriesFromBOSHTree(path, window.Start, window.End)
+			if err != nil {
+				return nil, err
+			}
+			entries[i], err = stream.Filter(And(window.Matcher(), matcher)).Collect()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return entries, nil
+	}
+
+	stream, err := converters.StreamFormat(path, format)
+	if err != nil {
+		return nil, err
+	}
+	err = stream.Filter(matcher).Each(func(entry Entry) error {
+		for i, window := range windows {
+			if window.Contains(entry.Timestamp) {
+				entries[i] = append(entries[i], entry)
+			}
+		}
+		return nil
+	})
+	return entries, err
+}
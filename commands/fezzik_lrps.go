@@ -9,7 +9,6 @@ import (
 
 	"github.com/gonum/plot"
 
-	"github.com/cloudfoundry-incubator/cicerone/converters"
 	. "github.com/cloudfoundry-incubator/cicerone/dsl"
 	"github.com/cloudfoundry-incubator/cicerone/viz"
 	"github.com/onsi/say"
@@ -37,7 +36,7 @@ func (f *FezzikLRPs) Command(outputDir string, args ...string) error {
 		return fmt.Errorf("First argument must be a path to a lager file, second must be a process guid")
 	}
 
-	e, err := converters.EntriesFromLagerFile(args[0])
+	e, err := loadEntries(args[0])
 	if err != nil {
 		return err
 	}
@@ -58,35 +57,35 @@ func (f *FezzikLRPs) Command(outputDir string, args ...string) error {
 
 	lrpStartTimelineDescription := TimelineDescription{
 		// Creating ActualLRP (proxy - this is the event emitted)
-		{"Creating-ALRP", MatchMessage(`creating-raw-actual-lrp.starting`), 1},
+		{Name: "Creating-ALRP", Matcher: MatchMessage(`creating-raw-actual-lrp.starting`), Squash: 1},
 		// Executor reserving container
-		{"Allocated", MatchMessage(`allocate-containers.finished-allocating-container`), 1},
-		{"Reserved-Container", MatchMessage(`claiming-lrp-container`), 1},
-		{"Claim-Request-Received", MatchMessage(`claim-actual-lrp.starting`), 1},
+		{Name: "Allocated", Matcher: MatchMessage(`allocate-containers.finished-allocating-container`), Squash: 1},
+		{Name: "Reserved-Container", Matcher: MatchMessage(`claiming-lrp-container`), Squash: 1},
+		{Name: "Claim-Request-Received", Matcher: MatchMessage(`claim-actual-lrp.starting`), Squash: 1},
 		// Rep marked LRP CLAIMED in BBS
-		{"Claimed-ALRP", MatchMessage(`claim-actual-lrp.succeeded`), 1},
+		{Name: "Claimed-ALRP", Matcher: MatchMessage(`claim-actual-lrp.succeeded`), Squash: 1},
 		// Executor created actual container in Garden
-		{"Created-Container", MatchMessage(`run-container.create-in-garden.succeeded-creating-garden-container`), 1},
+		{Name: "Created-Container", Matcher: MatchMessage(`run-container.create-in-garden.succeeded-creating-garden-container`), Squash: 1},
 		// Executor configured container (memory limits, CPU limits, port mappings, etc.)
-		{"Configured-Container", MatchMessage(`run-container.create-in-garden.succeeded-getting-garden-container-info`), 1},
+		{Name: "Configured-Container", Matcher: MatchMessage(`run-container.create-in-garden.succeeded-getting-garden-container-info`), Squash: 1},
 		// Fetching download
-		{"Fetched-Download", MatchMessage(`run-container.run.setup.download-step.fetch-complete`), 1},
+		{Name: "Fetched-Download", Matcher: MatchMessage(`run-container.run.setup.download-step.fetch-complete`), Squash: 1},
 		// Streamed download into container
-		{"Streamed-in-Download", MatchMessage(`run-container.run.setup.download-step.stream-in-complete`), 1},
+		{Name: "Streamed-in-Download", Matcher: MatchMessage(`run-container.run.setup.download-step.stream-in-complete`), Squash: 1},
 		// Started Running LRP (grace) in container
-		{"Launch-Process", And(MatchMessage(`garden-server.run.spawned`), RegExpMatcher(DataGetter("spec.Path"), `grace`)), 1},
+		{Name: "Launch-Process", Matcher: And(MatchMessage(`garden-server.run.spawned`), RegExpMatcher(DataGetter("spec.Path"), `grace`)), Squash: 1},
 		// Started Running monitor process (nc) in container
-		{"Launch-Monitor", And(MatchMessage(`garden-server.run.spawned`), RegExpMatcher(DataGetter("spec.Path"), `nc`)), 1},
+		{Name: "Launch-Monitor", Matcher: And(MatchMessage(`garden-server.run.spawned`), RegExpMatcher(DataGetter("spec.Path"), `nc`)), Squash: 1},
 		// Executor transitioning container to RUNNING
-		{"Container-Is-Running", MatchMessage(`run-container.run.run-step-process.succeeded-transitioning-to-running`), 1},
+		{Name: "Container-Is-Running", Matcher: MatchMessage(`run-container.run.run-step-process.succeeded-transitioning-to-running`), Squash: 1},
 		// Rep transitioned LRP to RUNNING in BBS
-		{"Running-In-BBS", MatchMessage(`start-actual-lrp.succeeded`), 1},
+		{Name: "Running-In-BBS", Matcher: MatchMessage(`start-actual-lrp.succeeded`), Squash: 1},
 		// Rep requesting container stop
-		{"Stopping", MatchMessage(`lrp-stopper.stop.stopping`), 1},
+		{Name: "Stopping", Matcher: MatchMessage(`lrp-stopper.stop.stopping`), Squash: 1},
 		// LRP has been cancelled
-		{"Stopped", MatchMessage(`run-container.run.run-step-process.step-cancelled`), 1},
+		{Name: "Stopped", Matcher: MatchMessage(`run-container.run.run-step-process.step-cancelled`), Squash: 1},
 		// Rep transitioned LRP to COMPLETED in BBS
-		{"Remove-From-BBS", MatchMessage(`run-container.run.run-step-process.succeeded-transitioning-to-complete`), 1},
+		{Name: "Remove-From-BBS", Matcher: MatchMessage(`run-container.run.run-step-process.succeeded-transitioning-to-complete`), Squash: 1},
 	}
 
 	lrpStartTimelines, err := byInstanceGuid.ConstructTimelines(lrpStartTimelineDescription)
@@ -189,6 +188,9 @@ func plotFezzikLRPTimelinesAndHistograms(timelines Timelines, outputDir string,
 	timelineBoard.AddSubPlot(p, viz.Rect{0, 0, 1.0, 1.0})
 	timelineBoard.Save(16.0, 10.0, filepath.Join(outputDir, prefix+"-timelines-by-vm.svg"))
 
+	swimlanes, height := viz.NewSwimlanesBoard("Swimlanes by VM", timelines)
+	swimlanes.Save(16.0, height, filepath.Join(outputDir, prefix+"-swimlanes.svg"))
+
 	timelines.SortByStartTime()
 	timelineBoard = &viz.Board{}
 	p, _ = plot.New()
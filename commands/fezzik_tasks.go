@@ -7,7 +7,6 @@ import (
 
 	"github.com/gonum/plot"
 
-	"github.com/cloudfoundry-incubator/cicerone/converters"
 	. "github.com/cloudfoundry-incubator/cicerone/dsl"
 	"github.com/cloudfoundry-incubator/cicerone/viz"
 	"github.com/onsi/say"
@@ -34,7 +33,7 @@ func (f *FezzikTasks) Command(outputDir string, args ...string) error {
 		return fmt.Errorf("First argument must be a lager file")
 	}
 
-	e, err := converters.EntriesFromLagerFile(args[0])
+	e, err := loadEntries(args[0])
 	if err != nil {
 		return err
 	}
@@ -46,37 +45,46 @@ func (f *FezzikTasks) Command(outputDir string, args ...string) error {
 	fmt.Println("BBSs that handled creates:", e.Filter(MatchMessage(`desire-task\.starting`)).GroupBy(GetVM).Keys)
 	fmt.Println("BBSs that handled resolves:", e.Filter(MatchMessage(`resolved-task`)).GroupBy(GetVM).Keys)
 
-	byTaskGuid := e.GroupBy(DataGetter("task-guid", "container-guid", "guid", "container.guid", "allocation-request.Guid", "handle"))
+	taskIdentifiers := []Getter{}
+	for _, key := range []string{"task-guid", "container-guid", "guid", "container.guid", "allocation-request.Guid", "handle"} {
+		taskIdentifiers = append(taskIdentifiers, DataGetter(key))
+	}
+	lineage := e.Lineage(taskIdentifiers...)
+	say.Println(0, say.Green("Task Lineages"))
+	say.Println(1, "%s", lineage.String())
+	largest := lineage.Largest()
+	say.Println(0, "Largest lineage links %d identifiers: %s", len(largest), strings.Join(largest, " => "))
+	byTaskGuid := e.GroupBy(lineage.Getter())
 
 	startToEndTimelineDescription := TimelineDescription{
 		// bbs says desire-task.starting when it hears about our task
-		{"Desiring-Task", MatchMessage(`desire-task\.starting`), 1},
+		{Name: "Desiring-Task", Matcher: MatchMessage(`desire-task\.starting`), Squash: 1},
 		// bbs says the task is persisted
-		{"Persisted-Task", MatchMessage(`desire-task\.succeeded-persisting-task`), 1},
+		{Name: "Persisted-Task", Matcher: MatchMessage(`desire-task\.succeeded-persisting-task`), Squash: 1},
 		// bbs says create.created after the auction has been submitted (this entails a round-trip to the auctioneer)
-		{"Auction-Submitted", MatchMessage(`desire-task\.finished`), 1},
+		{Name: "Auction-Submitted", Matcher: MatchMessage(`desire-task\.finished`), Squash: 1},
 		// executor says allocating-container when the rep asks it to allocate a container for the task (this measures how long it took the auction to place the task on the rep)
-		{"Allocating-Container", MatchMessage(`\.allocating-container`), 1},
+		{Name: "Allocating-Container", Matcher: MatchMessage(`\.allocating-container`), Squash: 1},
 		// the rep says processing-reserved-container when the executor emits the allocation event
-		{"Notified-Of-Allocation", MatchMessage(`\.processing-reserved-container`), 1},
+		{Name: "Notified-Of-Allocation", Matcher: MatchMessage(`\.processing-reserved-container`), Squash: 1},
 		// the rep says succeeded-starting-task when it succesfully transitions the task from PENDING to RUNNING in the BBS
-		{"Running-In-BBS", MatchMessage(`start-task\.finished`), 1},
+		{Name: "Running-In-BBS", Matcher: MatchMessage(`start-task\.finished`), Squash: 1},
 		// the executor says succeded-creating-container-in-garden when the garden container is created and ready to go
-		{"Created-Container", MatchMessage(`\.succeeded-creating-garden-container`), 1},
+		{Name: "Created-Container", Matcher: MatchMessage(`\.succeeded-creating-garden-container`), Squash: 1},
 		// setting up egress rules for task container
-		{"Set-Up-Container-Network", MatchMessage(`\.succeeded-setting-up-net-out`), 1},
-		{"Started-Running", MatchMessage(`\.run-step-process\.succeeded-transitioning-to-running`), 1},
-		{"Process-Created", MatchMessage(`successful-process-create`), 1},
-		{"Process-Exited", MatchMessage(`process-exit`), 1},
-		{"Finished-Running", MatchMessage(`\.run-step-process\.finished`), 1},
+		{Name: "Set-Up-Container-Network", Matcher: MatchMessage(`\.succeeded-setting-up-net-out`), Squash: 1},
+		{Name: "Started-Running", Matcher: MatchMessage(`\.run-step-process\.succeeded-transitioning-to-running`), Squash: 1},
+		{Name: "Process-Created", Matcher: MatchMessage(`successful-process-create`), Squash: 1},
+		{Name: "Process-Exited", Matcher: MatchMessage(`process-exit`), Squash: 1},
+		{Name: "Finished-Running", Matcher: MatchMessage(`\.run-step-process\.finished`), Squash: 1},
 		// the rep says that its fetching the result file
-		{"Fetching-Container-Result", MatchMessage(`\.fetching-container-result`), 1},
+		{Name: "Fetching-Container-Result", Matcher: MatchMessage(`\.fetching-container-result`), Squash: 1},
 		// the rep says task-processor.completing-task when it hears the task is complete
-		{"Fetched-Container-Result", MatchMessage(`task-processor\.completing-task`), 1},
+		{Name: "Fetched-Container-Result", Matcher: MatchMessage(`task-processor\.completing-task`), Squash: 1},
 		// the rep says succeeded-completing-task when it transitions the task from RUNNING to COMPLETE
-		{"Persisted-Completed", MatchMessage(`task-processor\.succeeded-completing-task`), 1},
+		{Name: "Persisted-Completed", Matcher: MatchMessage(`task-processor\.succeeded-completing-task`), Squash: 1},
 		// the bbs says resolved-task when it transitions the task to RESOLVED (after hitting the fezzik callback)
-		{"Resolved", MatchMessage(`resolved-task`), 1},
+		{Name: "Resolved", Matcher: MatchMessage(`resolved-task`), Squash: 1},
 	}
 
 	say.Println(0, say.Green("Distribution"))
@@ -101,16 +109,16 @@ func (f *FezzikTasks) Command(outputDir string, args ...string) error {
 
 	startToScheduledTimelineDescription := TimelineDescription{
 		// bbs says desire-task.starting when it hears about our task
-		{"Desiring-Task", MatchMessage(`desire-task\.starting`), 1},
-		{"Persisted-Task", MatchMessage(`desire-task\.succeeded-persisting-task`), 1},
+		{Name: "Desiring-Task", Matcher: MatchMessage(`desire-task\.starting`), Squash: 1},
+		{Name: "Persisted-Task", Matcher: MatchMessage(`desire-task\.succeeded-persisting-task`), Squash: 1},
 		// bbs says create.created after the auction has been submitted (this entails a round-trip to the auctioneer)
-		{"Auction-Submitted", MatchMessage(`desire-task\.finished`), 1},
+		{Name: "Auction-Submitted", Matcher: MatchMessage(`desire-task\.finished`), Squash: 1},
 		// executor says allocating-container when the rep asks it to allocate a container for the task (this measures how long it took the auction to place the task on the rep)
-		{"Allocating-Container", MatchMessage(`\.allocating-container`), 1},
+		{Name: "Allocating-Container", Matcher: MatchMessage(`\.allocating-container`), Squash: 1},
 		// the rep says processing-reserved-container when the executor emits the allocation event
-		{"Notified-Of-Allocation", MatchMessage(`\.processing-reserved-container`), 1},
+		{Name: "Notified-Of-Allocation", Matcher: MatchMessage(`\.processing-reserved-container`), Squash: 1},
 		// the rep says succeeded-starting-task when it succesfully transitions the task from PENDING to RUNNING in the BBS
-		{"Running-In-BBS", MatchMessage(`start-task\.finished`), 1},
+		{Name: "Running-In-BBS", Matcher: MatchMessage(`start-task\.finished`), Squash: 1},
 	}
 
 	startToScheduledTimelines, err := byTaskGuid.ConstructTimelines(startToScheduledTimelineDescription)
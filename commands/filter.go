@@ -0,0 +1,18 @@
+package commands
+
+import (
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+)
+
+//EntryFilter is applied by every command to the entries it loads.  It is set with the global --filter flag.
+var EntryFilter = True()
+
+//SetFilter parses the passed-in query (see dsl.ParseMatcher) and uses it as the EntryFilter
+func SetFilter(query string) error {
+	matcher, err := ParseMatcher(query)
+	if err != nil {
+		return err
+	}
+	EntryFilter = matcher
+	return nil
+}
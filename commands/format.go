@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/cicerone/converters"
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+)
+
+//EntryFormat names the converters format used to read log files.  It is set with the global --format flag.
+//When empty, each command's log files are sniffed to detect their format (see converters.Stream).
+var EntryFormat = ""
+
+//SetFormat validates the passed-in format name and uses it as the EntryFormat
+func SetFormat(name string) error {
+	if _, ok := converters.LookupFormat(name); !ok {
+		return fmt.Errorf("unknown format: %s (known formats: %s)", name, strings.Join(converters.FormatNames(), ", "))
+	}
+	EntryFormat = name
+	return nil
+}
+
+//streamEntries streams the log file(s) at path in the EntryFormat, keeping only the entries that pass the EntryFilter
+func streamEntries(path string) (*EntryStream, error) {
+	stream, err := converters.StreamFormat(path, EntryFormat)
+	if err != nil {
+		return nil, err
+	}
+	return stream.Filter(EntryFilter), nil
+}
+
+//loadEntries loads the log file(s) at path in the EntryFormat, keeping only the entries that pass the EntryFilter
+func loadEntries(path string) (Entries, error) {
+	stream, err := streamEntries(path)
+	if err != nil {
+		return nil, err
+	}
+	return stream.Collect()
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/onsi/say"
+)
+
+type ListOperations struct{}
+
+func (l *ListOperations) Usage() string {
+	return "operations LOG_FILE"
+}
+
+func (l *ListOperations) Description() string {
+	return `
+Finds every operation in LOG_FILE by pairing start-like lager messages
+with their terminal counterparts within each session:
+
+  foo.starting => foo.finished|succeeded|failed|complete|completed|done
+  foo.started  => foo.finished|succeeded|failed|complete|completed|done
+  foo.handling => foo.success|succeeded|failed|done
+
+and prints DTStats for each operation, sorted by the total time consumed.
+
+e.g. operations ~/workspace/performance/10-cells/cf-pushes/unoptimized-unified-bosh-logs.log
+`
+}
+
+func (l *ListOperations) Command(outputDir string, args ...string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Expected a log file")
+	}
+
+	entries, err := loadEntries(args[0])
+	if err != nil {
+		return err
+	}
+
+	operations := entries.Operations()
+	if len(operations) == 0 {
+		return fmt.Errorf("no operations found")
+	}
+
+	for _, stats := range operations.DTStatsSlice() {
+		say.Println(0, "%s %s", say.Green(stats.Name), say.Yellow("total: %s", stats.Durations.Total()))
+		stats.Name = ""
+		say.Println(1, stats.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+	"github.com/cloudfoundry-incubator/cicerone/viz"
+	"github.com/onsi/say"
+)
+
+type SessionTree struct{}
+
+func (s *SessionTree) Usage() string {
+	return "session-tree LOG_FILE SOURCE [SESSION]"
+}
+
+func (s *SessionTree) Description() string {
+	return `
+Builds the lager session tree for SOURCE (e.g. rep, auctioneer) in LOG_FILE
+and prints it: every session with its name, duration, number of entries and
+errors, indented under the session it is nested in.
+
+Pass SESSION (e.g. 4.12) to restrict the output to that session and its
+descendants.  Session IDs are only unique per process so there is one tree
+per VM.
+
+An icicle chart and an inverted icicle chart (root at the bottom) of the
+tree(s) are saved to OUTPUT_DIR.  Sessions that run concurrently under the
+same parent are drawn on separate rows and sessions that logged errors are
+drawn in red.
+
+e.g. session-tree ~/workspace/performance/10-cells/cf-pushes/unoptimized-unified-bosh-logs.log auctioneer 3.7
+`
+}
+
+func (s *SessionTree) Command(outputDir string, args ...string) error {
+	if len(args) != 2 && len(args) != 3 {
+		return fmt.Errorf("Expected a log file, a source and, optionally, a session")
+	}
+
+	stream, err := streamEntries(args[0])
+	if err != nil {
+		return err
+	}
+	entries, err := stream.Filter(MatchSource(args[1])).Collect()
+	if err != nil {
+		return err
+	}
+
+	nodes := []*SessionNode(entries.SessionTrees(args[1]))
+	name := args[1]
+	if len(args) == 3 {
+		nodes = entries.SessionTrees(args[1]).Find(args[2])
+		name = args[1] + "-" + strings.Replace(args[2], ".", "-", -1)
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("no sessions found")
+	}
+
+	for _, node := range nodes {
+		say.Println(0, say.Green(node.VM))
+		fmt.Println(node)
+		for _, errorEntry := range sessionErrors(node) {
+			say.Println(1, say.Red("[%s] %s: %s", errorEntry.Session, errorEntry.Message, errorEntry.Error))
+		}
+	}
+
+	height := viz.IcicleHeight(nodes)
+	err = viz.NewSessionIcicleBoard(nodes, false).Save(16.0, height, filepath.Join(outputDir, name+"-icicle.svg"))
+	if err != nil {
+		return err
+	}
+	return viz.NewSessionIcicleBoard(nodes, true).Save(16.0, height, filepath.Join(outputDir, name+"-inverted-icicle.svg"))
+}
+
+func sessionErrors(node *SessionNode) Entries {
+	errors := Entries{}
+	node.Walk(func(n *SessionNode, _ int) error {
+		errors = append(errors, n.Errors...)
+		return nil
+	})
+	return errors
+}
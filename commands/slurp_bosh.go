@@ -3,10 +3,12 @@ package commands
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
 	"github.com/cloudfoundry-incubator/cicerone/converters"
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
 )
 
 type SlurpBosh struct{}
@@ -22,9 +24,21 @@ containing the loglines between MIN_TIME and MAX_TIME (passed in as unix timesta
 
 A BOSH_TREE is a directory with sub-directories that look like JOB-INDEX
 each containing subdirectories that are the name of a process (e.g. executor)
-and contain log files.
+and contain log files.  Compressed (.gz, .bz2) log files are decompressed on the fly.
+
+BOSH_TREE can also be a BOSH logs tarball (e.g. cell_z1.0.2015-02-24-21-55-54.tgz
+as downloaded by bosh logs, or a tarball of a whole BOSH_TREE) or a directory
+of per-job tarballs.  Tarballs are read directly - there is no need to extract them.
+
+Anything else converters can load (a log file, a directory of log files or a glob,
+optionally in the format given by --format) is slurped too.
+
+If OUTPUT ends in .cicerone a compact, indexed cache file is written instead of
+lager JSON.  All commands that read lager files accept cache files as well.
 
 e.g. slurp-bosh ~/workspace/performance/10-cells/cf-pushes/unoptimized/bosh-logs/ 1424820500 1424828000 $HOME/workspace/performance/10-cells/cf-pushes/unoptimized-unified-bosh-logs.log
+     slurp-bosh ~/workspace/performance/10-cells/cf-pushes/unoptimized/bosh-logs/ 1424820500 1424828000 $HOME/workspace/performance/10-cells/cf-pushes/unoptimized-unified-bosh-logs.cicerone
+     slurp-bosh ~/Downloads/cell_z1.0.2015-02-24-21-55-54.tgz 1424820500 1424828000 $HOME/workspace/performance/cell_z1-0.log
 `
 }
 
@@ -41,15 +55,33 @@ func (f *SlurpBosh) Command(outputDir string, args ...string) error {
 	if err != nil {
 		return err
 	}
-	entries, err := converters.EntriesFromBOSHTree(args[0], time.Unix(minTimestamp, 0), time.Unix(maxTimestamp, 0))
+	stream, err := slurp(args[0], time.Unix(minTimestamp, 0), time.Unix(maxTimestamp, 0))
 	if err != nil {
 		return err
 	}
 
+	if filepath.Ext(args[3]) == converters.CacheFileExtension {
+		return converters.WriteCacheFile(args[3], stream.Filter(EntryFilter))
+	}
+
 	outputFile, err := os.Create(args[3])
 	if err != nil {
+		stream.Close()
 		return err
 	}
+	defer outputFile.Close()
 
-	return entries.WriteLagerFormatTo(outputFile)
+	return stream.Filter(EntryFilter).WriteLagerFormatTo(outputFile)
+}
+
+func slurp(path string, minTime time.Time, maxTime time.Time) (*EntryStream, error) {
+	if EntryFormat == "bosh" || (EntryFormat == "" && converters.IsBOSHTree(path)) {
+		return converters.StreamEntriesFromBOSHTree(path, minTime, maxTime)
+	}
+
+	stream, err := converters.StreamFormat(path, EntryFormat)
+	if err != nil {
+		return nil, err
+	}
+	return stream.Filter(MatchBetween(minTime, maxTime)), nil
 }
@@ -1,8 +1,8 @@
 package converters
 
 import (
+	"io"
 	"io/ioutil"
-	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -39,7 +39,17 @@ func init() {
 //
 // Job corresponds to the bosh job extracted from the directory
 // Index corresponds to the bosh index extracted from the directory
+//
+// Compressed log files are decompressed transparently and path may also point to a BOSH logs tarball (see StreamEntriesFromBOSHTarball)
 func EntriesFromBOSHTree(path string, minTime time.Time, maxTime time.Time) (Entries, error) {
+	if IsTarball(path) {
+		stream, err := StreamEntriesFromBOSHTarball(path, minTime, maxTime)
+		if err != nil {
+			return nil, err
+		}
+		return stream.Collect()
+	}
+
 	entries := map[string]map[string]map[string]Entries{}
 
 	infos, err := ioutil.ReadDir(path)
@@ -164,7 +174,7 @@ func entriesFromBOSHProcess(path string, minTime time.Time, maxTime time.Time, j
 
 		file := info.Name()
 
-		f, err := os.Open(filepath.Join(path, file))
+		f, err := OpenLogFile(filepath.Join(path, file))
 		if err != nil {
 			return nil, err
 		}
@@ -193,9 +203,189 @@ func entriesFromBOSHProcess(path string, minTime time.Time, maxTime time.Time, j
 
 			fileEntries = append(fileEntries, entry)
 		}
+		f.Close()
 		say.Println(0, "%s       %s/%d [%s] %s", say.Yellow("Done"), job, index, process, file)
 		entries[file] = fileEntries
 	}
 
 	return entries, nil
 }
+
+// StreamEntriesFromBOSHTree is the streaming counterpart to EntriesFromBOSHTree.
+//
+// Every log file in the tree is read lazily and the (time-ordered) files are merged,
+// so only one entry per file is held in memory at a time.  At most maxOpenBOSHFiles files are
+// kept open after peeking at their first entry; the others are reopened once the merge reaches
+// them, and every file is closed as soon as it is exhausted, so trees with many (rotated) files
+// don't run out of file descriptors.  Entries are annotated with Job and Index just like EntriesFromBOSHTree.
+//
+// path may also be a BOSH logs tarball, and tarballs found alongside the JOB-INDEX directories
+// are read too (see StreamEntriesFromBOSHTarball).
+func StreamEntriesFromBOSHTree(path string, minTime time.Time, maxTime time.Time) (*EntryStream, error) {
+	if IsTarball(path) {
+		return StreamEntriesFromBOSHTarball(path, minTime, maxTime)
+	}
+
+	streams := []*EntryStream{}
+	open := 0
+
+	vmInfos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vmInfo := range vmInfos {
+		if !vmInfo.IsDir() {
+			tarballPath := filepath.Join(path, vmInfo.Name())
+			if !IsTarball(tarballPath) {
+				continue
+			}
+			stream, err := StreamEntriesFromBOSHTarball(tarballPath, minTime, maxTime)
+			if err != nil {
+				MergeEntryStreams(streams...).Close()
+				return nil, err
+			}
+			streams = append(streams, stream)
+			continue
+		}
+
+		matches := boshTreeSubDirRegExp.FindStringSubmatch(vmInfo.Name())
+		if matches == nil {
+			continue
+		}
+
+		job := matches[1]
+		index, _ := strconv.Atoi(matches[2])
+
+		processInfos, err := ioutil.ReadDir(filepath.Join(path, vmInfo.Name()))
+		if err != nil {
+			MergeEntryStreams(streams...).Close()
+			return nil, err
+		}
+
+		for _, processInfo := range processInfos {
+			if !processInfo.IsDir() {
+				continue
+			}
+
+			processPath := filepath.Join(path, vmInfo.Name(), processInfo.Name())
+			fileInfos, err := ioutil.ReadDir(processPath)
+			if err != nil {
+				MergeEntryStreams(streams...).Close()
+				return nil, err
+			}
+
+			for _, fileInfo := range fileInfos {
+				if fileInfo.IsDir() {
+					continue
+				}
+
+				stream, err := lazyStreamEntriesFromBOSHFile(filepath.Join(processPath, fileInfo.Name()), minTime, maxTime, job, index, &open)
+				if err != nil {
+					MergeEntryStreams(streams...).Close()
+					return nil, err
+				}
+				streams = append(streams, stream)
+			}
+		}
+	}
+
+	return MergeEntryStreams(streams...), nil
+}
+
+// maxOpenBOSHFiles caps the number of files StreamEntriesFromBOSHTree keeps open after peeking at their first entry.
+const maxOpenBOSHFiles = 128
+
+// lazyStreamEntriesFromBOSHFile peeks at the first entry of the file.  While fewer than maxOpenBOSHFiles files are held open
+// (as counted by open) the file is kept open and streaming resumes where the peek left off.  Otherwise the file is closed and
+// only reopened (and rescanned) once that first entry has been consumed.  Either way it is closed as soon as it is exhausted.
+func lazyStreamEntriesFromBOSHFile(path string, minTime time.Time, maxTime time.Time, job string, index int, open *int) (*EntryStream, error) {
+	stream, err := streamEntriesFromBOSHFile(path, minTime, maxTime, job, index)
+	if err != nil {
+		return nil, err
+	}
+	first, err := stream.Next()
+	if err != nil {
+		stream.Close()
+		if err == io.EOF {
+			return Entries{}.Stream(), nil
+		}
+		return nil, err
+	}
+
+	held := *open < maxOpenBOSHFiles
+	if held {
+		*open++
+	} else {
+		stream.Close()
+		stream = nil
+	}
+
+	closeStream := func() error {
+		if stream == nil {
+			return nil
+		}
+		if held {
+			held = false
+			*open--
+		}
+		return stream.Close()
+	}
+
+	peeked := false
+	return NewEntryStream(func() (Entry, error) {
+		if !peeked {
+			peeked = true
+			return first, nil
+		}
+		if stream == nil {
+			stream, err = streamEntriesFromBOSHFile(path, minTime, maxTime, job, index)
+			if err != nil {
+				return Entry{}, err
+			}
+			//skip the entry we peeked at
+			if _, err := stream.Next(); err != nil {
+				closeStream()
+				return Entry{}, err
+			}
+		}
+		entry, err := stream.Next()
+		if err != nil {
+			closeStream()
+		}
+		return entry, err
+	}, closeStream), nil
+}
+
+func streamEntriesFromBOSHFile(path string, minTime time.Time, maxTime time.Time, job string, index int) (*EntryStream, error) {
+	f, err := OpenLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fileStream := newChugEntryStream(f, func(chugEntry chug.Entry) (Entry, error) {
+		entry, err := NewEntryFromChugLog(chugEntry)
+		if err != nil {
+			return Entry{}, err
+		}
+		entry.Job = job
+		entry.Index = index
+		return entry, nil
+	})
+
+	return NewEntryStream(func() (Entry, error) {
+		for {
+			entry, err := fileStream.Next()
+			if err != nil {
+				return Entry{}, err
+			}
+			if entry.Timestamp.Before(minTime) {
+				continue
+			}
+			if entry.Timestamp.After(maxTime) {
+				return Entry{}, io.EOF
+			}
+			return entry, nil
+		}
+	}, fileStream.Close), nil
+}
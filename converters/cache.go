@@ -0,0 +1,491 @@
+package converters
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+	"github.com/pivotal-golang/lager"
+)
+
+// CacheFileExtension is the conventional extension for Cicerone cache files
+const CacheFileExtension = ".cicerone"
+
+const cacheMagic = "CICERONE-CACHE-1"
+const cacheBlockSize = 4096
+
+// CacheQuery restricts the entries loaded from a cache file.  Zero values do not restrict anything.
+//
+// Sessions match the named session and all of its descendants (e.g. "4.12" matches "4.12" and "4.12.3").
+type CacheQuery struct {
+	MinTime  time.Time
+	MaxTime  time.Time
+	Sources  []string
+	VMs      []string
+	Sessions []string
+}
+
+// A cache file looks like:
+//
+// MAGIC | BLOCK | BLOCK | ... | INDEX | INDEX-OFFSET | MAGIC
+//
+// Each block holds up to cacheBlockSize consecutive entries.
+// The (gob encoded) index records the time range covered by each block
+// and, for every source, VM and root session, the blocks that mention it.
+type cacheIndex struct {
+	Blocks   []cacheBlock
+	Sources  map[string][]int
+	VMs      map[string][]int
+	Sessions map[string][]int
+}
+
+type cacheBlock struct {
+	Offset  int64
+	Length  int64
+	Count   int
+	MinTime int64
+	MaxTime int64
+}
+
+// WriteCacheFile consumes the passed-in stream and writes its entries to a cache file at path.
+//
+// Entries are stored in the order they are produced, so streams ordered by time make for the most effective time index.
+func WriteCacheFile(path string, stream *EntryStream) error {
+	f, err := os.Create(path)
+	if err != nil {
+		stream.Close()
+		return err
+	}
+	defer f.Close()
+
+	w := &cacheWriter{
+		out:    bufio.NewWriter(f),
+		offset: int64(len(cacheMagic)),
+		index: cacheIndex{
+			Sources:  map[string][]int{},
+			VMs:      map[string][]int{},
+			Sessions: map[string][]int{},
+		},
+	}
+
+	_, err = w.out.WriteString(cacheMagic)
+	if err != nil {
+		stream.Close()
+		return err
+	}
+
+	err = stream.Each(w.add)
+	if err != nil {
+		return err
+	}
+
+	err = w.finish()
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// IsCacheFile returns true if the file at path is a Cicerone cache file
+func IsCacheFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(cacheMagic))
+	_, err = io.ReadFull(f, magic)
+	return err == nil && string(magic) == cacheMagic
+}
+
+// EntriesFromCacheFile loads the entries in a cache file that satisfy the passed-in query
+func EntriesFromCacheFile(path string, query CacheQuery) (Entries, error) {
+	stream, err := StreamEntriesFromCacheFile(path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return stream.Collect()
+}
+
+// StreamEntriesFromCacheFile streams the entries in a cache file that satisfy the passed-in query.
+//
+// The cache's indexes are used to seek directly to the blocks that can contain matching entries;
+// other blocks are never read.
+func StreamEntriesFromCacheFile(path string, query CacheQuery) (*EntryStream, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := readCacheIndex(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	blocks := index.selectBlocks(query)
+	matcher := query.matcher()
+	pending := Entries{}
+
+	return NewEntryStream(func() (Entry, error) {
+		for {
+			for len(pending) > 0 {
+				entry := pending[0]
+				pending = pending[1:]
+				if matcher.Match(entry) {
+					return entry, nil
+				}
+			}
+
+			if len(blocks) == 0 {
+				return Entry{}, io.EOF
+			}
+
+			pending, err = readCacheBlock(f, index.Blocks[blocks[0]])
+			if err != nil {
+				return Entry{}, err
+			}
+			blocks = blocks[1:]
+		}
+	}, f.Close), nil
+}
+
+// Writing (private)
+
+type cacheWriter struct {
+	out    *bufio.Writer
+	offset int64
+	index  cacheIndex
+	block  bytes.Buffer
+	count  int
+	min    int64
+	max    int64
+}
+
+func (w *cacheWriter) add(entry Entry) error {
+	timestamp := entry.Timestamp.UnixNano()
+	if w.count == 0 || timestamp < w.min {
+		w.min = timestamp
+	}
+	if w.count == 0 || timestamp > w.max {
+		w.max = timestamp
+	}
+
+	blockNumber := len(w.index.Blocks)
+	addToCacheIndex(w.index.Sources, entry.Source, blockNumber)
+	addToCacheIndex(w.index.VMs, entry.VM(), blockNumber)
+	addToCacheIndex(w.index.Sessions, rootSession(entry.Session), blockNumber)
+
+	err := encodeCacheEntry(&w.block, entry)
+	if err != nil {
+		return err
+	}
+	w.count++
+
+	if w.count == cacheBlockSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *cacheWriter) flush() error {
+	if w.count == 0 {
+		return nil
+	}
+
+	n, err := w.out.Write(w.block.Bytes())
+	if err != nil {
+		return err
+	}
+
+	w.index.Blocks = append(w.index.Blocks, cacheBlock{
+		Offset:  w.offset,
+		Length:  int64(n),
+		Count:   w.count,
+		MinTime: w.min,
+		MaxTime: w.max,
+	})
+	w.offset += int64(n)
+	w.block.Reset()
+	w.count = 0
+	return nil
+}
+
+func (w *cacheWriter) finish() error {
+	err := w.flush()
+	if err != nil {
+		return err
+	}
+
+	err = gob.NewEncoder(w.out).Encode(w.index)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w.out, binary.BigEndian, w.offset)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.out.WriteString(cacheMagic)
+	if err != nil {
+		return err
+	}
+
+	return w.out.Flush()
+}
+
+func addToCacheIndex(index map[string][]int, key string, blockNumber int) {
+	blocks := index[key]
+	if len(blocks) > 0 && blocks[len(blocks)-1] == blockNumber {
+		return
+	}
+	index[key] = append(blocks, blockNumber)
+}
+
+func rootSession(session string) string {
+	return strings.Split(session, ".")[0]
+}
+
+func encodeCacheEntry(buffer *bytes.Buffer, entry Entry) error {
+	errorMessage := ""
+	if entry.Error != nil {
+		errorMessage = entry.Error.Error()
+	}
+
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return err
+	}
+
+	writeCacheVarint(buffer, entry.Timestamp.UnixNano())
+	writeCacheVarint(buffer, int64(entry.LogLevel))
+	writeCacheVarint(buffer, int64(entry.Index))
+	for _, s := range []string{entry.Source, entry.Message, entry.Session, errorMessage, entry.Trace, entry.Job, string(data)} {
+		writeCacheVarint(buffer, int64(len(s)))
+		buffer.WriteString(s)
+	}
+	return nil
+}
+
+func writeCacheVarint(buffer *bytes.Buffer, v int64) {
+	scratch := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutVarint(scratch, v)
+	buffer.Write(scratch[:n])
+}
+
+// Reading (private)
+
+func readCacheIndex(f *os.File) (cacheIndex, error) {
+	index := cacheIndex{}
+
+	if !IsCacheFile(f.Name()) {
+		return index, errors.New("not a cicerone cache file")
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return index, err
+	}
+
+	trailerLength := int64(8 + len(cacheMagic))
+	trailer := make([]byte, trailerLength)
+	_, err = f.ReadAt(trailer, info.Size()-trailerLength)
+	if err != nil {
+		return index, err
+	}
+	if string(trailer[8:]) != cacheMagic {
+		return index, errors.New("truncated cicerone cache file")
+	}
+
+	indexOffset := int64(binary.BigEndian.Uint64(trailer[:8]))
+	if indexOffset < int64(len(cacheMagic)) || indexOffset > info.Size()-trailerLength {
+		return index, errors.New("corrupt cicerone cache file: invalid index offset")
+	}
+	err = gob.NewDecoder(io.NewSectionReader(f, indexOffset, info.Size()-trailerLength-indexOffset)).Decode(&index)
+	if err != nil {
+		return index, err
+	}
+
+	for _, block := range index.Blocks {
+		if !block.within(indexOffset) {
+			return index, errors.New("corrupt cicerone cache file: invalid block")
+		}
+	}
+	return index, nil
+}
+
+// within returns true if the block lies between the leading magic and the index and doesn't claim more entries than it has bytes
+func (block cacheBlock) within(indexOffset int64) bool {
+	if block.Offset < int64(len(cacheMagic)) || block.Length < 0 || block.Length > indexOffset-block.Offset {
+		return false
+	}
+	return block.Count >= 0 && int64(block.Count) <= block.Length
+}
+
+func readCacheBlock(f *os.File, block cacheBlock) (Entries, error) {
+	data := make([]byte, block.Length)
+	_, err := f.ReadAt(data, block.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bytes.NewReader(data)
+	entries := make(Entries, 0, block.Count)
+	for i := 0; i < block.Count; i++ {
+		entry, err := decodeCacheEntry(reader)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func decodeCacheEntry(reader *bytes.Reader) (Entry, error) {
+	entry := Entry{}
+
+	ints := make([]int64, 3)
+	for i := range ints {
+		v, err := binary.ReadVarint(reader)
+		if err != nil {
+			return Entry{}, err
+		}
+		ints[i] = v
+	}
+
+	strs := make([]string, 7)
+	for i := range strs {
+		length, err := binary.ReadVarint(reader)
+		if err != nil {
+			return Entry{}, err
+		}
+		if length < 0 || length > int64(reader.Len()) {
+			return Entry{}, errors.New("corrupt cicerone cache file: invalid string length")
+		}
+		s := make([]byte, length)
+		_, err = io.ReadFull(reader, s)
+		if err != nil {
+			return Entry{}, err
+		}
+		strs[i] = string(s)
+	}
+
+	entry.Timestamp = time.Unix(0, ints[0])
+	entry.LogLevel = lager.LogLevel(ints[1])
+	entry.Index = int(ints[2])
+	entry.Source = strs[0]
+	entry.Message = strs[1]
+	entry.Session = strs[2]
+	if strs[3] != "" {
+		entry.Error = errors.New(strs[3])
+	}
+	entry.Trace = strs[4]
+	entry.Job = strs[5]
+
+	err := json.Unmarshal([]byte(strs[6]), &entry.Data)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+func (index cacheIndex) selectBlocks(query CacheQuery) []int {
+	selected := []int{}
+	for i, block := range index.Blocks {
+		if !query.MinTime.IsZero() && block.MaxTime < query.MinTime.UnixNano() {
+			continue
+		}
+		if !query.MaxTime.IsZero() && block.MinTime > query.MaxTime.UnixNano() {
+			continue
+		}
+		selected = append(selected, i)
+	}
+
+	sessions := []string{}
+	for _, session := range query.Sessions {
+		sessions = append(sessions, rootSession(session))
+	}
+
+	selected = intersectCacheBlocks(selected, index.Sources, query.Sources)
+	selected = intersectCacheBlocks(selected, index.VMs, query.VMs)
+	selected = intersectCacheBlocks(selected, index.Sessions, sessions)
+
+	return selected
+}
+
+func intersectCacheBlocks(selected []int, index map[string][]int, keys []string) []int {
+	if len(keys) == 0 {
+		return selected
+	}
+
+	allowed := map[int]bool{}
+	for _, key := range keys {
+		for _, block := range index[key] {
+			allowed[block] = true
+		}
+	}
+
+	intersection := []int{}
+	for _, block := range selected {
+		if allowed[block] {
+			intersection = append(intersection, block)
+		}
+	}
+	sort.Ints(intersection)
+	return intersection
+}
+
+func (query CacheQuery) matcher() Matcher {
+	matchers := []Matcher{}
+	if !query.MinTime.IsZero() {
+		matchers = append(matchers, Not(MatchBefore(query.MinTime)))
+	}
+	if !query.MaxTime.IsZero() {
+		matchers = append(matchers, Not(MatchAfter(query.MaxTime)))
+	}
+	if len(query.Sources) > 0 {
+		matchers = append(matchers, matchOneOf(GetSource, query.Sources))
+	}
+	if len(query.VMs) > 0 {
+		matchers = append(matchers, matchOneOf(GetVM, query.VMs))
+	}
+	if len(query.Sessions) > 0 {
+		sessions := query.Sessions
+		matchers = append(matchers, MatcherFunc(func(entry Entry) bool {
+			for _, session := range sessions {
+				if entry.Session == session || strings.HasPrefix(entry.Session, session+".") {
+					return true
+				}
+			}
+			return false
+		}))
+	}
+	return And(matchers...)
+}
+
+func matchOneOf(getter Getter, values []string) Matcher {
+	return MatcherFunc(func(entry Entry) bool {
+		value, _ := getter.Get(entry)
+		for _, v := range values {
+			if value == v {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,143 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/chug"
+)
+
+func newTestCacheEntries(n int) Entries {
+	entries := Entries{}
+	for i := 0; i < n; i++ {
+		entry := Entry{
+			LogEntry: chug.LogEntry{
+				Timestamp: time.Unix(1424820000+int64(i), 0),
+				LogLevel:  lager.INFO,
+				Source:    []string{"rep", "bbs"}[i%2],
+				Message:   "test.message",
+				Session:   "4.12",
+				Data:      lager.Data{"guid": "abc"},
+			},
+			Job:   "cell",
+			Index: i % 3,
+		}
+		if i%10 == 0 {
+			entry.LogLevel = lager.ERROR
+			entry.Error = errors.New("boom")
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func tempCachePath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "cicerone-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "entries"+CacheFileExtension), func() { os.RemoveAll(dir) }
+}
+
+func TestCacheFileRoundTrip(t *testing.T) {
+	path, cleanup := tempCachePath(t)
+	defer cleanup()
+
+	entries := newTestCacheEntries(2*cacheBlockSize + 10)
+	err := WriteCacheFile(path, entries.Stream())
+	if err != nil {
+		t.Fatalf("failed to write cache file: %s", err)
+	}
+	if !IsCacheFile(path) {
+		t.Fatalf("expected %s to be a cache file", path)
+	}
+
+	loaded, err := EntriesFromCacheFile(path, CacheQuery{})
+	if err != nil {
+		t.Fatalf("failed to read cache file: %s", err)
+	}
+	if !reflect.DeepEqual(loaded, entries) {
+		t.Errorf("expected %d entries to round-trip, got %d", len(entries), len(loaded))
+	}
+
+	query := CacheQuery{
+		MinTime: entries[cacheBlockSize+5].Timestamp,
+		MaxTime: entries[cacheBlockSize+9].Timestamp,
+		Sources: []string{"rep"},
+	}
+	loaded, err = EntriesFromCacheFile(path, query)
+	if err != nil {
+		t.Fatalf("failed to query cache file: %s", err)
+	}
+	expected := Entries{entries[cacheBlockSize+6], entries[cacheBlockSize+8]}
+	if !reflect.DeepEqual(loaded, expected) {
+		t.Errorf("expected the query to return %d entries, got %d", len(expected), len(loaded))
+	}
+}
+
+func TestCacheFileRejectsTruncatedFiles(t *testing.T) {
+	path, cleanup := tempCachePath(t)
+	defer cleanup()
+
+	err := WriteCacheFile(path, newTestCacheEntries(10).Stream())
+	if err != nil {
+		t.Fatalf("failed to write cache file: %s", err)
+	}
+	data, _ := ioutil.ReadFile(path)
+	ioutil.WriteFile(path, data[:len(data)-4], 0644)
+
+	_, err = EntriesFromCacheFile(path, CacheQuery{})
+	if err == nil {
+		t.Errorf("expected a truncated cache file to fail to load")
+	}
+}
+
+func TestCacheFileRejectsCorruptIndexes(t *testing.T) {
+	path, cleanup := tempCachePath(t)
+	defer cleanup()
+
+	blocks := map[string]cacheBlock{
+		"a huge length":             {Offset: int64(len(cacheMagic)), Length: 1 << 40, Count: 1},
+		"a huge count":              {Offset: int64(len(cacheMagic)), Length: 10, Count: 1 << 40},
+		"a negative length":         {Offset: int64(len(cacheMagic)), Length: -1, Count: 0},
+		"an offset inside magic":    {Offset: 0, Length: 10, Count: 1},
+		"an offset past the index":  {Offset: 1 << 40, Length: 10, Count: 1},
+		"a length overflowing past": {Offset: int64(len(cacheMagic)) + 1, Length: 1<<63 - 1, Count: 1},
+	}
+
+	for description, block := range blocks {
+		buffer := &bytes.Buffer{}
+		buffer.WriteString(cacheMagic)
+		buffer.Write(make([]byte, 20))
+		indexOffset := int64(buffer.Len())
+		gob.NewEncoder(buffer).Encode(cacheIndex{Blocks: []cacheBlock{block}})
+		binary.Write(buffer, binary.BigEndian, indexOffset)
+		buffer.WriteString(cacheMagic)
+		ioutil.WriteFile(path, buffer.Bytes(), 0644)
+
+		_, err := EntriesFromCacheFile(path, CacheQuery{})
+		if err == nil {
+			t.Errorf("expected a block with %s to be rejected", description)
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	buffer.WriteString(cacheMagic)
+	binary.Write(buffer, binary.BigEndian, int64(1<<40))
+	buffer.WriteString(cacheMagic)
+	ioutil.WriteFile(path, buffer.Bytes(), 0644)
+	_, err := EntriesFromCacheFile(path, CacheQuery{})
+	if err == nil {
+		t.Errorf("expected an index offset past the end of the file to be rejected")
+	}
+}
@@ -0,0 +1,52 @@
+package converters
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+var bzip2Magic = []byte("BZh")
+
+// OpenLogFile opens the file at path for reading, transparently decompressing gzip and bzip2 files.
+//
+// Compression is detected by the file's magic bytes, so rotated logs (e.g. executor.log.1.gz) and
+// compressed files with unconventional names are handled alike.  Closing the returned reader closes the file.
+func OpenLogFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := decompress(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return readCloser{reader, f}, nil
+}
+
+// decompress wraps the passed-in reader with a decompressor if its content is gzip or bzip2 compressed
+func decompress(reader io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(reader)
+	magic, _ := buffered.Peek(len(bzip2Magic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(buffered)
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return bzip2.NewReader(buffered), nil
+	default:
+		return buffered, nil
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -2,5 +2,8 @@
 Converters convert from various sources to Cicerone entries.
 
 Note that Cicerone entries are just dressed up lager logs.
+
+Load and Stream pick the right converter by sniffing the file, directory or glob they are given.
+Additional formats can be made available to them with RegisterFormat.
 */
 package converters
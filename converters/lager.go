@@ -1,29 +1,59 @@
 package converters
 
 import (
-	"os"
+	"io"
 
 	. "github.com/cloudfoundry-incubator/cicerone/dsl"
 	"github.com/pivotal-golang/lager/chug"
 )
 
 func EntriesFromLagerFile(filename string) (Entries, error) {
-	file, err := os.Open(filename)
+	stream, err := StreamEntriesFromLagerFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	out := make(chan chug.Entry)
-	go chug.Chug(file, out)
+	return stream.Collect()
+}
 
-	entries := Entries{}
-	for chugEntry := range out {
-		entry, err := NewEntryFromChugLog(chugEntry)
-		if err != nil {
-			continue
-		}
-		entries = append(entries, entry)
+// StreamEntriesFromLagerFile returns an EntryStream that reads the lager file one line at a time
+//
+// Cicerone cache files (see WriteCacheFile) and gzip/bzip2 compressed files are detected and read transparently.
+func StreamEntriesFromLagerFile(filename string) (*EntryStream, error) {
+	if IsCacheFile(filename) {
+		return StreamEntriesFromCacheFile(filename, CacheQuery{})
+	}
+
+	file, err := OpenLogFile(filename)
+	if err != nil {
+		return nil, err
 	}
 
-	return entries, nil
+	return newChugEntryStream(file, NewEntryFromChugLog), nil
+}
+
+// newChugEntryStream runs chug over the passed-in reader and converts each chug entry into an Entry.
+// Chug entries that fail to convert are skipped.
+func newChugEntryStream(reader io.ReadCloser, convert func(chug.Entry) (Entry, error)) *EntryStream {
+	out := make(chan chug.Entry)
+	go chug.Chug(reader, out)
+
+	return NewEntryStream(func() (Entry, error) {
+		for chugEntry := range out {
+			entry, err := convert(chugEntry)
+			if err != nil {
+				continue
+			}
+			return entry, nil
+		}
+		return Entry{}, io.EOF
+	}, func() error {
+		err := reader.Close()
+		//chug stops once the reader is closed; drain whatever it is still trying to send
+		go func() {
+			for _ = range out {
+			}
+		}()
+		return err
+	})
 }
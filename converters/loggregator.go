@@ -23,7 +23,13 @@ func init() {
 // Job and Source correspond to the loggregator source (e.g. APP, CELL)
 // Index corresponds to the loggregator index (e.g. APP/2 yields 2)
 func EntriesFromLoggregatorLogs(filename string) (Entries, error) {
-	data, err := ioutil.ReadFile(filename)
+	file, err := OpenLogFile(filename)
+	if err != nil {
+		return Entries{}, err
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
 	if err != nil {
 		return Entries{}, err
 	}
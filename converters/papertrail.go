@@ -1,7 +1,6 @@
 package converters
 
 import (
-	"os"
 	"regexp"
 	"strconv"
 
@@ -20,24 +19,22 @@ func init() {
 // Job corresponds to the BOSH job
 // Index corresponds to the BOSH index
 func EntriesFromPapertrailFile(filename string) (Entries, error) {
-	file, err := os.Open(filename)
+	stream, err := StreamEntriesFromPapertrailFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	out := make(chan chug.Entry)
-	go chug.Chug(file, out)
+	return stream.Collect()
+}
 
-	entries := Entries{}
-	for chugEntry := range out {
-		entry, err := newEntryFromPapertrail(chugEntry)
-		if err != nil {
-			continue
-		}
-		entries = append(entries, entry)
+// StreamEntriesFromPapertrailFile returns an EntryStream that reads the papertrail file one line at a time
+func StreamEntriesFromPapertrailFile(filename string) (*EntryStream, error) {
+	file, err := OpenLogFile(filename)
+	if err != nil {
+		return nil, err
 	}
 
-	return entries, nil
+	return newChugEntryStream(file, newEntryFromPapertrail), nil
 }
 
 func newEntryFromPapertrail(chugEntry chug.Entry) (Entry, error) {
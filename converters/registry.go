@@ -0,0 +1,286 @@
+package converters
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+	"github.com/onsi/say"
+)
+
+// Format describes a log format that Load knows how to read.
+//
+// Sniff returns true if the file (or directory) at path looks like it is in this format.
+// Stream reads the entries at path.
+type Format struct {
+	Name   string
+	Sniff  func(path string) bool
+	Stream func(path string) (*EntryStream, error)
+}
+
+var formats = []Format{}
+
+// farFuture is used as the maximum time when loading BOSH trees without a time window
+var farFuture = time.Unix(1<<40, 0)
+
+func init() {
+	RegisterFormat(Format{
+		Name:   "cache",
+		Sniff:  IsCacheFile,
+		Stream: func(path string) (*EntryStream, error) { return StreamEntriesFromCacheFile(path, CacheQuery{}) },
+	})
+	RegisterFormat(Format{
+		Name:  "bosh",
+		Sniff: IsBOSHTree,
+		Stream: func(path string) (*EntryStream, error) {
+			return StreamEntriesFromBOSHTree(path, time.Time{}, farFuture)
+		},
+	})
+	RegisterFormat(Format{
+		Name:   "papertrail",
+		Sniff:  sniffLines(func(line string) bool { return papertrailRegExp.MatchString(line) && strings.Contains(line, "{") }),
+		Stream: StreamEntriesFromPapertrailFile,
+	})
+	RegisterFormat(Format{
+		Name: "syslog",
+		Sniff: sniffLines(func(line string) bool {
+			return syslog5424RegExp.MatchString(line) || syslog3164RegExp.MatchString(line)
+		}),
+		Stream: StreamEntriesFromSyslogFile,
+	})
+	RegisterFormat(Format{
+		Name:  "loggregator",
+		Sniff: sniffLines(loggregatorRegExp.MatchString),
+		Stream: func(path string) (*EntryStream, error) {
+			entries, err := EntriesFromLoggregatorLogs(path)
+			if err != nil {
+				return nil, err
+			}
+			return entries.Stream(), nil
+		},
+	})
+	RegisterFormat(Format{
+		Name:   "lager",
+		Sniff:  sniffLines(func(line string) bool { return strings.HasPrefix(line, "{") && strings.Contains(line, `"timestamp"`) }),
+		Stream: StreamEntriesFromLagerFile,
+	})
+}
+
+// RegisterFormat makes a format available to Load.
+//
+// Formats are sniffed in the order they are registered.  RegisterFormat panics if a format with the same name has already been registered.
+func RegisterFormat(format Format) {
+	if _, ok := LookupFormat(format.Name); ok {
+		panic("converters: format " + format.Name + " is already registered")
+	}
+	formats = append(formats, format)
+}
+
+// LookupFormat returns the registered format with the passed-in name
+func LookupFormat(name string) (Format, bool) {
+	for _, format := range formats {
+		if format.Name == name {
+			return format, true
+		}
+	}
+	return Format{}, false
+}
+
+// FormatNames returns the names of all registered formats, in the order they are sniffed
+func FormatNames() []string {
+	names := []string{}
+	for _, format := range formats {
+		names = append(names, format.Name)
+	}
+	return names
+}
+
+// DetectFormat sniffs the file or directory at path and returns the first registered format that recognizes it.
+//
+// Files that no format recognizes (e.g. an empty lager file, or one with a non-JSON preamble) are read as lager,
+// which skips the lines it can't parse.
+func DetectFormat(path string) Format {
+	if format, ok := sniffFormat(path); ok {
+		return format
+	}
+	format, _ := LookupFormat("lager")
+	return format
+}
+
+func sniffFormat(path string) (Format, bool) {
+	for _, format := range formats {
+		if format.Sniff(path) {
+			return format, true
+		}
+	}
+	return Format{}, false
+}
+
+// Load loads all the entries at path, detecting its format.  See Stream.
+func Load(path string) (Entries, error) {
+	return LoadFormat(path, "")
+}
+
+// LoadFormat loads all the entries at path in the named format.  See StreamFormat.
+func LoadFormat(path string, formatName string) (Entries, error) {
+	stream, err := StreamFormat(path, formatName)
+	if err != nil {
+		return nil, err
+	}
+	return stream.Collect()
+}
+
+// Stream streams the entries at path, detecting its format.
+//
+// path can be:
+//
+// - a file in any registered format (compressed files are decompressed transparently)
+// - a directory: either a BOSH tree or a directory of log files
+// - a glob (e.g. logs/*.log.gz)
+//
+// When path resolves to several files their entries are merged by time.  Files in a directory or glob that no format
+// recognizes are skipped with a warning.
+func Stream(path string) (*EntryStream, error) {
+	return StreamFormat(path, "")
+}
+
+// StreamFormat is like Stream but reads every file in the named format instead of detecting it.  An empty formatName detects the format.
+func StreamFormat(path string, formatName string) (*EntryStream, error) {
+	var format Format
+	if formatName != "" {
+		var ok bool
+		format, ok = LookupFormat(formatName)
+		if !ok {
+			return nil, fmt.Errorf("unknown format: %s (known formats: %s)", formatName, strings.Join(FormatNames(), ", "))
+		}
+	}
+
+	paths, err := expandLogPath(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := []*EntryStream{}
+	for _, p := range paths {
+		f := format
+		if formatName == "" && p == path {
+			f = DetectFormat(p)
+		} else if formatName == "" {
+			var ok bool
+			f, ok = sniffFormat(p)
+			if !ok {
+				say.Println(0, say.Yellow("Skipping %s: unrecognized log format", p))
+				continue
+			}
+		}
+
+		stream, err := f.Stream(p)
+		if err != nil {
+			MergeEntryStreams(streams...).Close()
+			return nil, err
+		}
+		streams = append(streams, stream)
+	}
+
+	if len(streams) == 0 {
+		return nil, fmt.Errorf("%s contains no recognizable log files (known formats: %s)", path, strings.Join(FormatNames(), ", "))
+	}
+	if len(streams) == 1 {
+		return streams[0], nil
+	}
+	return MergeEntryStreams(streams...), nil
+}
+
+// IsBOSHTree returns true if path is a BOSH logs tarball or a directory that contains JOB-INDEX directories or BOSH logs tarballs
+func IsBOSHTree(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() {
+		return IsTarball(path)
+	}
+
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return false
+	}
+	for _, info := range infos {
+		if info.IsDir() && boshTreeSubDirRegExp.MatchString(info.Name()) {
+			return true
+		}
+		if !info.IsDir() && IsTarball(filepath.Join(path, info.Name())) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandLogPath resolves globs and directories (that aren't in the passed-in format) into the files to load
+func expandLogPath(path string, format Format) ([]string, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) && strings.ContainsAny(path, "*?[") {
+		paths, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%s matches no files", path)
+		}
+		return paths, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() || (format.Sniff != nil && format.Sniff(path)) || (format.Sniff == nil && IsBOSHTree(path)) {
+		return []string{path}, nil
+	}
+
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, info := range infos {
+		if !info.IsDir() && !strings.HasPrefix(info.Name(), ".") {
+			paths = append(paths, filepath.Join(path, info.Name()))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%s contains no log files", path)
+	}
+	return paths, nil
+}
+
+// sniffLines returns a Sniff function that is satisfied if any of the first few non-empty lines of a file match
+func sniffLines(match func(line string) bool) func(path string) bool {
+	return func(path string) bool {
+		f, err := OpenLogFile(path)
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+
+		reader := bufio.NewReader(f)
+		for lines := 0; lines < 10; {
+			line, err := reader.ReadString('\n')
+			line = strings.TrimSpace(line)
+			if line != "" {
+				if match(line) {
+					return true
+				}
+				lines++
+			}
+			if err != nil {
+				return false
+			}
+		}
+		return false
+	}
+}
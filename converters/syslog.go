@@ -0,0 +1,228 @@
+package converters
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/chug"
+)
+
+var syslog5424RegExp *regexp.Regexp
+var syslog3164RegExp *regexp.Regexp
+var syslogStructuredDataParamRegExp *regexp.Regexp
+var syslogHostnameRegExp *regexp.Regexp
+
+func init() {
+	syslog5424RegExp = regexp.MustCompile(`^<(\d{1,3})>\d{1,2} (\S+) (\S+) (\S+) (\S+) (\S+) (-|(?:\[(?:[^\]\\]|\\.)*\])+) ?(.*)$`)
+	syslog3164RegExp = regexp.MustCompile(`^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d|\d{4}-\d\d-\d\dT\S+) (\S+) ([^\s:\[]+)(?:\[[^\]]*\])?: ?(.*)$`)
+	syslogStructuredDataParamRegExp = regexp.MustCompile(`([^\s=\]]+)="((?:[^"\\]|\\.)*)"`)
+	syslogHostnameRegExp = regexp.MustCompile(`^([a-zA-Z0-9_-]+?)[-/.](\d+)$`)
+}
+
+// EntriesFromSyslogFile takes a file of RFC 5424 or RFC 3164 syslog lines (as written by rsyslog or a syslog drain)
+// and generates Cicerone entries.
+//
+// See StreamEntriesFromSyslogFile for how syslog fields map onto entries.
+func EntriesFromSyslogFile(filename string) (Entries, error) {
+	stream, err := StreamEntriesFromSyslogFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return stream.Collect()
+}
+
+// StreamEntriesFromSyslogFile returns an EntryStream that reads the syslog file one line at a time
+//
+// Job and Index come from the structured data (job/index or BOSH's group/id parameters), a papertrail style
+// [job=JOB index=INDEX] tag or, failing that, a hostname that looks like JOB-INDEX or JOB/INDEX.
+// Source corresponds to the syslog APP-NAME (RFC 5424) or TAG (RFC 3164).
+//
+// When the syslog message holds a lager JSON payload the entry is built from the payload (whose source and timestamp take precedence).
+// Otherwise the entry's Message is the syslog message and its LogLevel is derived from the syslog severity.
+// Lines that aren't syslog lines are skipped, as are lines without a lager payload whose timestamp is missing (RFC 5424's -) or invalid.
+//
+// RFC 3164 timestamps carry neither a year nor a timezone.  They are read as UTC (which is what BOSH VMs log in) and placed in the
+// most recent year that doesn't put them more than a day in the future, so December logs read in January keep last year.
+func StreamEntriesFromSyslogFile(filename string) (*EntryStream, error) {
+	file, err := OpenLogFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(file)
+
+	return NewEntryStream(func() (Entry, error) {
+		for {
+			line, err := reader.ReadString('\n')
+			if len(line) > 0 {
+				entry, ok := NewEntryFromSyslogLine(strings.TrimRight(line, "\r\n"))
+				if ok {
+					return entry, nil
+				}
+			}
+			if err != nil {
+				return Entry{}, err
+			}
+		}
+	}, file.Close), nil
+}
+
+// NewEntryFromSyslogLine parses a single RFC 5424 or RFC 3164 syslog line
+func NewEntryFromSyslogLine(line string) (Entry, bool) {
+	var priority, timestamp, hostname, appName, structuredData, message string
+
+	if results := syslog5424RegExp.FindStringSubmatch(line); results != nil {
+		priority, timestamp, hostname, appName, structuredData, message = results[1], results[2], results[3], results[4], results[7], results[8]
+	} else if results := syslog3164RegExp.FindStringSubmatch(line); results != nil {
+		priority, timestamp, hostname, appName, message = results[1], results[2], results[3], results[4], results[5]
+	} else {
+		return Entry{}, false
+	}
+
+	entry, isLager := newEntryFromLagerPayload(message)
+	if !isLager {
+		t, ok := parseSyslogTimestamp(timestamp)
+		if !ok {
+			return Entry{}, false
+		}
+		entry = Entry{}
+		entry.Timestamp = t
+		entry.LogLevel = syslogLogLevel(priority)
+		entry.Message = message
+	}
+	if entry.Source == "" && appName != "-" {
+		entry.Source = appName
+	}
+
+	if entry.Job == "" {
+		entry.Job, entry.Index = syslogJobAndIndex(hostname, structuredData, message)
+	}
+
+	return entry, true
+}
+
+func newEntryFromLagerPayload(message string) (Entry, bool) {
+	start := strings.Index(message, "{")
+	if start < 0 {
+		return Entry{}, false
+	}
+
+	payload := lager.LogFormat{}
+	err := json.Unmarshal([]byte(strings.TrimSpace(message[start:])), &payload)
+	if err != nil || payload.Timestamp == "" || payload.Message == "" {
+		return Entry{}, false
+	}
+
+	seconds, err := strconv.ParseFloat(payload.Timestamp, 64)
+	if err != nil {
+		return Entry{}, false
+	}
+
+	log := chug.LogEntry{
+		Timestamp: time.Unix(0, int64(seconds*float64(time.Second))),
+		LogLevel:  payload.LogLevel,
+		Source:    payload.Source,
+		Message:   payload.Message,
+		Data:      payload.Data,
+	}
+	if log.Data == nil {
+		log.Data = lager.Data{}
+	}
+	if session, ok := log.Data["session"].(string); ok {
+		log.Session = session
+		delete(log.Data, "session")
+	}
+	if errorMessage, ok := log.Data["error"].(string); ok {
+		log.Error = errors.New(errorMessage)
+		delete(log.Data, "error")
+	}
+	if trace, ok := log.Data["trace"].(string); ok {
+		log.Trace = trace
+		delete(log.Data, "trace")
+	}
+
+	entry, err := NewEntryFromChugLog(chug.Entry{
+		IsLager: true,
+		Raw:     []byte(message),
+		Log:     log,
+	})
+	return entry, err == nil
+}
+
+//syslogNow is the time RFC 3164 timestamps are placed relative to
+var syslogNow = time.Now
+
+func parseSyslogTimestamp(timestamp string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err == nil {
+		return t, true
+	}
+
+	//RFC 3164 timestamps have no year: pick the most recent year that doesn't put the timestamp in the future
+	t, err = time.Parse(time.Stamp, timestamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	now := syslogNow().UTC()
+	year := now.Year()
+	if time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).After(now.AddDate(0, 0, 1)) {
+		year--
+	}
+	return time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC), true
+}
+
+func syslogLogLevel(priority string) lager.LogLevel {
+	p, err := strconv.Atoi(priority)
+	if err != nil {
+		return lager.INFO
+	}
+
+	switch severity := p % 8; {
+	case severity <= 2:
+		return lager.FATAL
+	case severity == 3:
+		return lager.ERROR
+	case severity == 7:
+		return lager.DEBUG
+	default:
+		return lager.INFO
+	}
+}
+
+func syslogJobAndIndex(hostname string, structuredData string, message string) (string, int) {
+	params := map[string]string{}
+	for _, param := range syslogStructuredDataParamRegExp.FindAllStringSubmatch(structuredData, -1) {
+		params[param[1]] = param[2]
+	}
+
+	for _, keys := range [][2]string{{"job", "index"}, {"group", "id"}} {
+		job, hasJob := params[keys[0]]
+		index, err := strconv.Atoi(params[keys[1]])
+		if hasJob && err == nil {
+			return job, index
+		}
+	}
+
+	if result := papertrailRegExp.FindStringSubmatch(message); len(result) == 3 {
+		index, _ := strconv.Atoi(result[2])
+		return result[1], index
+	}
+
+	if result := syslogHostnameRegExp.FindStringSubmatch(hostname); result != nil {
+		index, _ := strconv.Atoi(result[2])
+		return result[1], index
+	}
+
+	if hostname == "-" {
+		return "", 0
+	}
+	return hostname, 0
+}
@@ -0,0 +1,95 @@
+package converters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+func TestNewEntryFromSyslogLine(t *testing.T) {
+	originalNow := syslogNow
+	syslogNow = func() time.Time { return time.Date(2016, time.January, 2, 12, 0, 0, 0, time.UTC) }
+	defer func() { syslogNow = originalNow }()
+
+	cases := []struct {
+		description string
+		line        string
+		timestamp   time.Time
+		level       lager.LogLevel
+		source      string
+		message     string
+		job         string
+		index       int
+	}{
+		{
+			"an RFC 5424 line with BOSH structured data",
+			`<11>1 2015-12-31T23:59:58.5Z 10.0.16.5 rep 1234 - [instance@47450 group="cell" id="3"] failed to do the thing`,
+			time.Date(2015, time.December, 31, 23, 59, 58, 500000000, time.UTC), lager.ERROR, "rep", "failed to do the thing", "cell", 3,
+		},
+		{
+			"an RFC 5424 line without structured data",
+			`<14>1 2015-12-31T23:59:58Z cell-2 rep - - - hello`,
+			time.Date(2015, time.December, 31, 23, 59, 58, 0, time.UTC), lager.INFO, "rep", "hello", "cell", 2,
+		},
+		{
+			"an RFC 3164 line from last year",
+			`<15>Dec 31 23:59:58 cell/1 rep[1234]: debugging`,
+			time.Date(2015, time.December, 31, 23, 59, 58, 0, time.UTC), lager.DEBUG, "rep", "debugging", "cell", 1,
+		},
+		{
+			"an RFC 3164 line from this year",
+			`Jan  2 11:00:00 cell-0 rep: hello`,
+			time.Date(2016, time.January, 2, 11, 0, 0, 0, time.UTC), lager.INFO, "rep", "hello", "cell", 0,
+		},
+		{
+			"an RFC 3164 line with a papertrail tag",
+			`<14>Jan  2 11:00:00 10.0.16.5 vcap: [job=brain index=4] hello`,
+			time.Date(2016, time.January, 2, 11, 0, 0, 0, time.UTC), lager.INFO, "vcap", "[job=brain index=4] hello", "brain", 4,
+		},
+		{
+			"an RFC 5424 line with a lager payload",
+			`<14>1 2015-12-31T23:59:58Z cell-2 vcap.rep - - - {"timestamp":"1424820600.5","source":"rep","message":"rep.started","log_level":2,"data":{}}`,
+			time.Unix(1424820600, 500000000), lager.ERROR, "rep", "rep.started", "cell", 2,
+		},
+		{
+			"an RFC 5424 line with a lager payload and a nil timestamp",
+			`<14>1 - cell-2 vcap.rep - - - {"timestamp":"1424820600","source":"rep","message":"rep.started","log_level":1,"data":{}}`,
+			time.Unix(1424820600, 0), lager.INFO, "rep", "rep.started", "cell", 2,
+		},
+	}
+
+	for _, c := range cases {
+		entry, ok := NewEntryFromSyslogLine(c.line)
+		if !ok {
+			t.Errorf("%s: failed to parse", c.description)
+			continue
+		}
+		if !entry.Timestamp.Equal(c.timestamp) {
+			t.Errorf("%s: expected timestamp %s, got %s", c.description, c.timestamp, entry.Timestamp)
+		}
+		if entry.LogLevel != c.level || entry.Source != c.source || entry.Message != c.message {
+			t.Errorf("%s: expected %d %s %q, got %d %s %q", c.description, c.level, c.source, c.message, entry.LogLevel, entry.Source, entry.Message)
+		}
+		if entry.Job != c.job || entry.Index != c.index {
+			t.Errorf("%s: expected %s/%d, got %s", c.description, c.job, c.index, entry.VM())
+		}
+	}
+}
+
+func TestNewEntryFromSyslogLineSkipsMalformedLines(t *testing.T) {
+	lines := []string{
+		``,
+		`not a syslog line`,
+		`{"timestamp":"1424820600","source":"rep","message":"rep.started","log_level":1,"data":{}}`,
+		`<14>1 - cell-2 rep - - - a message without a timestamp`,
+		`<14>1 yesterday cell-2 rep - - - a message with an invalid timestamp`,
+		`<14>Feb 30 11:00:00 cell-0 rep: an impossible date`,
+	}
+
+	for _, line := range lines {
+		if entry, ok := NewEntryFromSyslogLine(line); ok {
+			t.Errorf("expected %q to be skipped, got %#v", line, entry)
+		}
+	}
+}
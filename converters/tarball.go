@@ -0,0 +1,180 @@
+package converters
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+	"github.com/onsi/say"
+	"github.com/pivotal-golang/lager/chug"
+)
+
+var boshTarballRegExp *regexp.Regexp
+var tarballExtensions = []string{".tar.gz", ".tar.bz2", ".tgz", ".tbz2", ".tar"}
+
+func init() {
+	boshTarballRegExp = regexp.MustCompile(`^([a-zA-Z0-9_-]+)[.-](\d+)([.-]|$)`)
+}
+
+// IsTarball returns true if the file at path is a (possibly gzip or bzip2 compressed) tar archive
+func IsTarball(path string) bool {
+	f, err := OpenLogFile(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, 512)
+	_, err = io.ReadFull(f, header)
+	return err == nil && bytes.HasPrefix(header[257:], []byte("ustar"))
+}
+
+// StreamEntriesFromBOSHTarball streams the entries in a BOSH logs tarball without extracting it.
+//
+// Two layouts are understood:
+//
+// - a per-job tarball, as downloaded by `bosh logs JOB INDEX`, named JOB.INDEX.TIMESTAMP.tgz (or JOB-INDEX.tgz) and containing /PROCESS/FILE
+// - a tarball containing a BOSH tree (see EntriesFromBOSHTree) or a collection of per-job tarballs
+//
+// Compressed files (e.g. rotated .log.1.gz files) within the tarball are decompressed transparently.
+// Since a tarball can only be read sequentially the entries in [minTime, maxTime] are held in memory.
+func StreamEntriesFromBOSHTarball(path string, minTime time.Time, maxTime time.Time) (*EntryStream, error) {
+	f, err := OpenLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	job, index, ok := jobAndIndexFromTarballName(filepath.Base(path))
+	streams, err := streamsFromBOSHTarball(f, minTime, maxTime, job, index, ok)
+	if err != nil {
+		return nil, err
+	}
+
+	return MergeEntryStreams(streams...), nil
+}
+
+func streamsFromBOSHTarball(reader io.Reader, minTime time.Time, maxTime time.Time, job string, index int, isJobTarball bool) ([]*EntryStream, error) {
+	streams := []*EntryStream{}
+
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return streams, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !header.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		name := strings.TrimPrefix(path.Clean(header.Name), "./")
+
+		if nestedJob, nestedIndex, ok := jobAndIndexFromTarballName(path.Base(name)); ok && hasTarballExtension(name) {
+			nested, err := decompress(tarReader)
+			if err != nil {
+				return nil, err
+			}
+			nestedStreams, err := streamsFromBOSHTarball(nested, minTime, maxTime, nestedJob, nestedIndex, true)
+			if err != nil {
+				return nil, err
+			}
+			streams = append(streams, nestedStreams...)
+			continue
+		}
+
+		fileJob, fileIndex, process, ok := jobIndexAndProcessFromTarballPath(name)
+		if !ok && isJobTarball {
+			fileJob, fileIndex = job, index
+			process, ok = processFromTarballPath(name)
+		}
+		if !ok {
+			continue
+		}
+
+		say.Println(0, "%s %s/%d [%s] %s", say.Green("Processing"), fileJob, fileIndex, process, path.Base(name))
+		entries, err := entriesFromBOSHReader(tarReader, minTime, maxTime, fileJob, fileIndex)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, entries.Stream())
+	}
+}
+
+// entriesFromBOSHReader reads the reader to the end, keeping the lager entries in [minTime, maxTime]
+func entriesFromBOSHReader(reader io.Reader, minTime time.Time, maxTime time.Time, job string, index int) (Entries, error) {
+	decompressed, err := decompress(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := newChugEntryStream(ioutil.NopCloser(decompressed), func(chugEntry chug.Entry) (Entry, error) {
+		entry, err := NewEntryFromChugLog(chugEntry)
+		if err != nil {
+			return Entry{}, err
+		}
+		entry.Job = job
+		entry.Index = index
+		return entry, nil
+	})
+
+	entries := Entries{}
+	err = stream.Each(func(entry Entry) error {
+		if !entry.Timestamp.Before(minTime) && !entry.Timestamp.After(maxTime) {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func jobAndIndexFromTarballName(name string) (string, int, bool) {
+	matches := boshTarballRegExp.FindStringSubmatch(name)
+	if matches == nil {
+		return "", 0, false
+	}
+	index, _ := strconv.Atoi(matches[2])
+	return matches[1], index, true
+}
+
+// processFromTarballPath extracts the process from a per-job tarball path (PROCESS/FILE)
+func processFromTarballPath(name string) (string, bool) {
+	components := strings.Split(name, "/")
+	if len(components) < 2 {
+		return "", false
+	}
+	return components[0], true
+}
+
+// jobIndexAndProcessFromTarballPath extracts the job, index and process from a BOSH tree path ([PREFIX/]JOB-INDEX/PROCESS/FILE)
+func jobIndexAndProcessFromTarballPath(name string) (string, int, string, bool) {
+	components := strings.Split(name, "/")
+	for i := len(components) - 3; i >= 0; i-- {
+		matches := boshTreeSubDirRegExp.FindStringSubmatch(components[i])
+		if matches == nil || matches[0] != components[i] {
+			continue
+		}
+		index, _ := strconv.Atoi(matches[2])
+		return matches[1], index, components[i+1], true
+	}
+	return "", 0, "", false
+}
+
+func hasTarballExtension(name string) bool {
+	for _, extension := range tarballExtensions {
+		if strings.HasSuffix(name, extension) {
+			return true
+		}
+	}
+	return false
+}
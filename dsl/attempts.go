@@ -0,0 +1,87 @@
+package dsl
+
+import "fmt"
+
+//Attempt annotates a Timeline constructed from one of several lifecycles logged under the same grouping key
+//(e.g. an LRP instance that crashed and restarted, or a task retried after a failed auction).
+//
+//Key is the grouping key and Number counts the lifecycles from 1, in the order in which they begin.
+type Attempt struct {
+	Key    interface{}
+	Number int
+}
+
+func (a Attempt) String() string {
+	return fmt.Sprintf("%v#%d", a.Key, a.Number)
+}
+
+//SplitAttempts splits the Entries into successive attempts: a new attempt begins at every Entry that matches the passed-in Matcher.
+//Entries that precede the first match belong to the first attempt.
+func (e Entries) SplitAttempts(matcher Matcher) []Entries {
+	attempts := []Entries{}
+	current := Entries{}
+	started := false
+	for _, entry := range e {
+		if matcher.Match(entry) {
+			if started {
+				attempts = append(attempts, current)
+				current = Entries{}
+			}
+			started = true
+		}
+		current = append(current, entry)
+	}
+	if len(current) > 0 {
+		attempts = append(attempts, current)
+	}
+	return attempts
+}
+
+//SplitAttempts splits the Entries in each group into attempts (see Entries.SplitAttempts).
+//The Keys of the returned GroupedEntries are Attempts: each group yields Attempts numbered 1, 2, ...
+func (g *GroupedEntries) SplitAttempts(matcher Matcher) *GroupedEntries {
+	split := make([][]Entries, len(g.Entries))
+	g.eachGroupInParallel(func(i int) {
+		split[i] = g.Entries[i].SplitAttempts(matcher)
+	})
+
+	attempts := NewGroupedEntries()
+	for i, key := range g.Keys {
+		for j, entries := range split[i] {
+			attempts.AppendEntries(Attempt{Key: key, Number: j + 1}, entries)
+		}
+	}
+	return attempts
+}
+
+//ConstructAttemptTimelines is like ConstructTimelines but constructs one Timeline per attempt: a new attempt begins
+//whenever the first TimelinePoint matches again.  The Timelines are annotated with Attempts.
+func (g *GroupedEntries) ConstructAttemptTimelines(description TimelineDescription) (Timelines, error) {
+	return g.SplitAttempts(description[0].Matcher).ConstructTimelines(description)
+}
+
+//GroupByAttempt groups Timelines by attempt number, so that e.g. first starts and restarts can be analyzed separately.
+//Timelines that are not annotated with an Attempt count as first attempts.  Groups are ordered by attempt number.
+func (t Timelines) GroupByAttempt() *GroupedTimelines {
+	numbers := make([]int, len(t))
+	maxNumber := 0
+	for i, timeline := range t {
+		numbers[i] = 1
+		if attempt, ok := timeline.Annotation.(Attempt); ok {
+			numbers[i] = attempt.Number
+		}
+		if numbers[i] > maxNumber {
+			maxNumber = numbers[i]
+		}
+	}
+
+	grouped := NewGroupedTimelines()
+	for number := 1; number <= maxNumber; number++ {
+		for i, timeline := range t {
+			if numbers[i] == number {
+				grouped.Append(number, timeline)
+			}
+		}
+	}
+	return grouped
+}
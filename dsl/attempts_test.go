@@ -0,0 +1,93 @@
+package dsl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pivotal-golang/lager"
+)
+
+func entryMessages(entries Entries) []string {
+	m := []string{}
+	for _, entry := range entries {
+		m = append(m, entry.Message)
+	}
+	return m
+}
+
+func TestSplitAttempts(t *testing.T) {
+	entries := Entries{
+		newTestEntry("noise", 1, nil),
+		newTestEntry("start", 2, nil),
+		newTestEntry("running", 3, nil),
+		newTestEntry("start", 4, nil),
+		newTestEntry("start", 5, nil),
+		newTestEntry("running", 6, nil),
+	}
+
+	attempts := entries.SplitAttempts(MatchMessage("start"))
+	expected := [][]string{{"noise", "start", "running"}, {"start"}, {"start", "running"}}
+	if len(attempts) != len(expected) {
+		t.Fatalf("expected %d attempts, got %d", len(expected), len(attempts))
+	}
+	for i, attempt := range attempts {
+		if !reflect.DeepEqual(entryMessages(attempt), expected[i]) {
+			t.Errorf("expected attempt %d to be %v, got %v", i+1, expected[i], entryMessages(attempt))
+		}
+	}
+
+	if attempts := entries.SplitAttempts(MatchMessage("never")); len(attempts) != 1 || len(attempts[0]) != len(entries) {
+		t.Errorf("expected entries without a match to form a single attempt, got %d attempts", len(attempts))
+	}
+	if attempts := (Entries{}).SplitAttempts(MatchMessage("start")); len(attempts) != 0 {
+		t.Errorf("expected no entries to yield no attempts, got %d", len(attempts))
+	}
+}
+
+func TestConstructAttemptTimelines(t *testing.T) {
+	entries := Entries{
+		newTestEntry("start", 1, lager.Data{"guid": "a"}),
+		newTestEntry("start", 2, lager.Data{"guid": "b"}),
+		newTestEntry("running", 3, lager.Data{"guid": "b"}),
+		newTestEntry("start", 4, lager.Data{"guid": "a"}),
+		newTestEntry("start", 5, lager.Data{"guid": "a"}),
+		newTestEntry("running", 6, lager.Data{"guid": "a"}),
+	}
+	description := TimelineDescription{
+		{Name: "start", Matcher: MatchMessage("start"), Squash: 1},
+		{Name: "running", Matcher: MatchMessage("running"), Squash: 1},
+	}
+
+	timelines, err := entries.GroupBy(DataGetter("guid")).ConstructAttemptTimelines(description)
+	if err != nil {
+		t.Fatalf("failed to construct timelines: %s", err)
+	}
+
+	expected := []struct {
+		attempt  Attempt
+		complete bool
+	}{
+		{Attempt{"a", 1}, false},
+		{Attempt{"a", 2}, false},
+		{Attempt{"a", 3}, true},
+		{Attempt{"b", 1}, true},
+	}
+	if len(timelines) != len(expected) {
+		t.Fatalf("expected %d timelines, got %d", len(expected), len(timelines))
+	}
+	for i, timeline := range timelines {
+		if timeline.Annotation != expected[i].attempt || timeline.IsComplete() != expected[i].complete {
+			t.Errorf("expected timeline %d to be %s (complete: %t), got %v (complete: %t)", i, expected[i].attempt, expected[i].complete, timeline.Annotation, timeline.IsComplete())
+		}
+	}
+
+	grouped := timelines.GroupByAttempt()
+	if !reflect.DeepEqual(grouped.Keys, []interface{}{1, 2, 3}) {
+		t.Fatalf("expected attempts 1, 2 and 3, got %v", grouped.Keys)
+	}
+	for i, n := range []int{2, 1, 1} {
+		if len(grouped.Timelines[i]) != n {
+			t.Errorf("expected %d timelines for attempt %d, got %d", n, i+1, len(grouped.Timelines[i]))
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package dsl
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+//CausalPairs returns, for each group, an EntryPair joining the first Entry matching cause with the first Entry matching effect.
+//
+//The cause must happen before the effect (e.g. the BBS sending a request must precede the rep handling it)
+//so EntryPairs with negative DTs point at clock skew between the VMs that emitted the two entries.
+//Unlike Timelines.EntryPairs, pairs with negative DTs are kept.
+//
+//The Key associated with the group becomes the Annotation associated with the EntryPair.
+func (g *GroupedEntries) CausalPairs(cause Matcher, effect Matcher) EntryPairs {
+	pairs := EntryPairs{}
+	g.EachGroup(func(key interface{}, entries Entries) error {
+		causeEntry, foundCause := entries.First(cause)
+		effectEntry, foundEffect := entries.First(effect)
+		if foundCause && foundEffect {
+			pairs = append(pairs, EntryPair{
+				FirstEntry:  causeEntry,
+				SecondEntry: effectEntry,
+				Annotation:  key,
+			})
+		}
+		return nil
+	})
+	return pairs
+}
+
+//ClockSkew is the estimated clock skew of a single VM (see Entry.VM())
+//
+//Offset is how far the VM's clock runs ahead of Reference's clock: subtracting Offset from the VM's timestamps corrects them.
+//Pairs is the number of causal EntryPairs that relate the VM to other VMs.
+//NegativeBefore and NegativeAfter count the causal EntryPairs involving the VM that have negative DTs before and after correction.
+type ClockSkew struct {
+	VM             string
+	Reference      string
+	Offset         time.Duration
+	Pairs          int
+	NegativeBefore int
+	NegativeAfter  int
+}
+
+//ClockSkews is a collection of ClockSkew estimates, one per VM
+type ClockSkews []ClockSkew
+
+//EstimateClockSkews estimates the clock skew of every VM that appears in the passed-in causal EntryPairs
+//(in each pair the FirstEntry must causally precede the SecondEntry -- see GroupedEntries.CausalPairs).
+//
+//For every pair of VMs A and B the smallest observed A->B DT bounds how far B's clock can run behind A's
+//and the smallest B->A DT bounds how far it can run ahead.  When both directions are observed the skew is
+//estimated as the midpoint of the two bounds (i.e. the minimal latencies in each direction are assumed to be equal).
+//When only one direction is observed the skew is corrected only as much as needed to make every DT non-negative.
+//
+//The pairwise estimates are then reconciled into per-VM offsets (a weighted least-squares fit) relative to a reference VM:
+//the VM with the most causal pairs in each connected group of VMs.
+func EstimateClockSkews(pairs EntryPairs) ClockSkews {
+	bounds := map[vmPair]time.Duration{}
+	pairCounts := map[vmPair]int{}
+	counts := map[string]int{}
+	for _, pair := range pairs {
+		from, to := pair.FirstEntry.VM(), pair.SecondEntry.VM()
+		counts[from] += 1
+		counts[to] += 1
+		if from == to {
+			continue
+		}
+		key := vmPair{from, to}
+		bound, ok := bounds[key]
+		if !ok || pair.DT() < bound {
+			bounds[key] = pair.DT()
+		}
+		pairCounts[key] += 1
+	}
+
+	//estimates[{a, b}] is the estimated offset of b relative to a
+	estimates := map[vmPair]float64{}
+	weights := map[vmPair]float64{}
+	neighbors := map[string][]string{}
+	for key, upper := range bounds {
+		reverse := vmPair{key.to, key.from}
+		if _, done := estimates[reverse]; done {
+			continue
+		}
+
+		estimate := math.Min(float64(upper), 0)
+		if lower, bidirectional := bounds[reverse]; bidirectional {
+			estimate = float64(upper-lower) / 2.0
+		}
+
+		estimates[key] = estimate
+		estimates[reverse] = -estimate
+		weights[key] = float64(pairCounts[key] + pairCounts[reverse])
+		weights[reverse] = weights[key]
+		neighbors[key.from] = append(neighbors[key.from], key.to)
+		neighbors[key.to] = append(neighbors[key.to], key.from)
+	}
+
+	vms := []string{}
+	for vm := range counts {
+		vms = append(vms, vm)
+	}
+	sort.Strings(vms)
+
+	offsets := map[string]float64{}
+	references := map[string]string{}
+	for _, component := range vmComponents(vms, neighbors) {
+		reference := component[0]
+		for _, vm := range component {
+			if counts[vm] > counts[reference] {
+				reference = vm
+			}
+		}
+		for _, vm := range component {
+			references[vm] = reference
+		}
+		fitClockOffsets(component, reference, neighbors, estimates, weights, offsets)
+	}
+
+	skews := ClockSkews{}
+	for _, vm := range vms {
+		skews = append(skews, ClockSkew{
+			VM:        vm,
+			Reference: references[vm],
+			Offset:    time.Duration(offsets[vm]),
+			Pairs:     counts[vm],
+		})
+	}
+
+	for _, pair := range pairs {
+		corrected := skews.CorrectEntry(pair.SecondEntry).Timestamp.Sub(skews.CorrectEntry(pair.FirstEntry).Timestamp)
+		for i := range skews {
+			if skews[i].VM != pair.FirstEntry.VM() && skews[i].VM != pair.SecondEntry.VM() {
+				continue
+			}
+			if pair.DT() < 0 {
+				skews[i].NegativeBefore += 1
+			}
+			if corrected < 0 {
+				skews[i].NegativeAfter += 1
+			}
+		}
+	}
+
+	return skews
+}
+
+//Offset returns the estimated offset of the passed-in VM (zero for unknown VMs)
+func (c ClockSkews) Offset(vm string) time.Duration {
+	for _, skew := range c {
+		if skew.VM == vm {
+			return skew.Offset
+		}
+	}
+	return 0
+}
+
+//CorrectEntry returns a copy of the passed-in Entry with its timestamp corrected for its VM's clock skew
+func (c ClockSkews) CorrectEntry(entry Entry) Entry {
+	entry.Timestamp = entry.Timestamp.Add(-c.Offset(entry.VM()))
+	return entry
+}
+
+//Correct returns a copy of the passed-in Entries with each timestamp corrected for its VM's clock skew.
+//The returned Entries are sorted by (corrected) time.
+func (c ClockSkews) Correct(entries Entries) Entries {
+	offsets := map[string]time.Duration{}
+	for _, skew := range c {
+		offsets[skew.VM] = skew.Offset
+	}
+
+	corrected := make(Entries, len(entries))
+	for i, entry := range entries {
+		entry.Timestamp = entry.Timestamp.Add(-offsets[entry.VM()])
+		corrected[i] = entry
+	}
+	sort.Stable(corrected)
+
+	return corrected
+}
+
+//String produces a table of the estimated skew per VM
+func (c ClockSkews) String() string {
+	s := []string{fmt.Sprintf("%-24s %-24s %14s %8s %16s", "VM", "Reference", "Offset", "Pairs", "Negative DTs")}
+	for _, skew := range c {
+		s = append(s, fmt.Sprintf("%-24s %-24s %14s %8d %7d => %-5d", skew.VM, skew.Reference, skew.Offset, skew.Pairs, skew.NegativeBefore, skew.NegativeAfter))
+	}
+	return strings.Join(s, "\n")
+}
+
+type vmPair struct {
+	from string
+	to   string
+}
+
+func vmComponents(vms []string, neighbors map[string][]string) [][]string {
+	visited := map[string]bool{}
+	components := [][]string{}
+	for _, vm := range vms {
+		if visited[vm] {
+			continue
+		}
+		component := []string{}
+		queue := []string{vm}
+		visited[vm] = true
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			component = append(component, current)
+			for _, neighbor := range neighbors[current] {
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					queue = append(queue, neighbor)
+				}
+			}
+		}
+		sort.Strings(component)
+		components = append(components, component)
+	}
+	return components
+}
+
+//fitClockOffsets solves for the offsets that best agree with the pairwise estimates (Gauss-Seidel on the weighted least-squares normal equations)
+func fitClockOffsets(component []string, reference string, neighbors map[string][]string, estimates map[vmPair]float64, weights map[vmPair]float64, offsets map[string]float64) {
+	for _, vm := range component {
+		offsets[vm] = 0
+	}
+
+	for iteration := 0; iteration < 10000; iteration++ {
+		maxChange := 0.0
+		for _, vm := range component {
+			if vm == reference {
+				continue
+			}
+			sum, totalWeight := 0.0, 0.0
+			for _, neighbor := range neighbors[vm] {
+				key := vmPair{neighbor, vm}
+				sum += weights[key] * (offsets[neighbor] + estimates[key])
+				totalWeight += weights[key]
+			}
+			updated := sum / totalWeight
+			maxChange = math.Max(maxChange, math.Abs(updated-offsets[vm]))
+			offsets[vm] = updated
+		}
+		if maxChange < float64(time.Nanosecond) {
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package dsl
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestPair(fromJob string, from float64, toJob string, to float64) EntryPair {
+	first, second := newTestEntry("cause", from, nil), newTestEntry("effect", to, nil)
+	first.Job, second.Job = fromJob, toJob
+	return EntryPair{FirstEntry: first, SecondEntry: second}
+}
+
+func TestEstimateClockSkewsBidirectional(t *testing.T) {
+	//the cell's clock runs 100ms ahead of the bbs' and messages take 10ms (or more) in either direction
+	pairs := EntryPairs{
+		newTestPair("bbs", 1.000, "cell", 1.110),
+		newTestPair("bbs", 2.000, "cell", 2.150),
+		newTestPair("cell", 3.100, "bbs", 3.010),
+		newTestPair("cell", 4.100, "bbs", 4.050),
+	}
+
+	skews := EstimateClockSkews(pairs)
+	if len(skews) != 2 {
+		t.Fatalf("expected a skew per VM, got %d", len(skews))
+	}
+	if skews.Offset("bbs/0") != 0 {
+		t.Errorf("expected bbs/0 to be the reference, got an offset of %s", skews.Offset("bbs/0"))
+	}
+	if offset := skews.Offset("cell/0"); offset != 100*time.Millisecond {
+		t.Errorf("expected cell/0 to run 100ms ahead, got %s", offset)
+	}
+	for _, skew := range skews {
+		if skew.Reference != "bbs/0" || skew.Pairs != 4 || skew.NegativeBefore != 2 || skew.NegativeAfter != 0 {
+			t.Errorf("unexpected skew %#v", skew)
+		}
+	}
+}
+
+func TestEstimateClockSkewsUnidirectional(t *testing.T) {
+	//only cell => bbs pairs are observed: the skew is corrected just enough to make every DT non-negative
+	pairs := EntryPairs{
+		newTestPair("cell", 1.100, "bbs", 1.010),
+		newTestPair("cell", 2.100, "bbs", 2.050),
+	}
+
+	skews := EstimateClockSkews(pairs)
+	if offset := skews.Offset("cell/0"); offset != 90*time.Millisecond {
+		t.Errorf("expected cell/0 to be corrected by 90ms, got %s", offset)
+	}
+	corrected := skews.CorrectEntry(pairs[0].SecondEntry).Timestamp.Sub(skews.CorrectEntry(pairs[0].FirstEntry).Timestamp)
+	if corrected != 0 {
+		t.Errorf("expected the tightest pair to have a corrected DT of 0, got %s", corrected)
+	}
+}
+
+func TestEstimateClockSkewsReconcilesTransitiveEstimates(t *testing.T) {
+	//brain runs 50ms and cell 100ms ahead of the bbs, and all three VMs talk to each other
+	pairs := EntryPairs{
+		newTestPair("bbs", 1.000, "brain", 1.060),
+		newTestPair("brain", 2.050, "bbs", 2.010),
+		newTestPair("brain", 3.050, "cell", 3.110),
+		newTestPair("cell", 4.100, "brain", 4.060),
+		newTestPair("bbs", 5.000, "cell", 5.110),
+		newTestPair("cell", 6.100, "bbs", 6.010),
+	}
+
+	skews := EstimateClockSkews(pairs)
+	expected := map[string]time.Duration{"bbs/0": 0, "brain/0": 50 * time.Millisecond, "cell/0": 100 * time.Millisecond}
+	for vm, offset := range expected {
+		if difference := skews.Offset(vm) - offset; difference < -time.Microsecond || difference > time.Microsecond {
+			t.Errorf("expected %s to have an offset of %s, got %s", vm, offset, skews.Offset(vm))
+		}
+	}
+}
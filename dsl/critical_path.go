@@ -0,0 +1,247 @@
+package dsl
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+//CriticalPath attributes the elapsed time of a complete Timeline to its TimelinePoints
+//
+//Total is the time between the Timeline's first and last entries.  Durations[i] is the time taken to reach TimelinePoint i
+//from the closest preceding TimelinePoint that is present and Shares[i] is the fraction of Total it accounts for.
+//The first TimelinePoint present starts the clock: its Duration and Share (and those of missing Optional TimelinePoints) are zero.
+type CriticalPath struct {
+	Timeline  Timeline
+	Total     time.Duration
+	Durations Durations
+	Shares    []float64
+}
+
+//CriticalPath computes the CriticalPath of the Timeline.  It returns false if the Timeline is incomplete or takes no time at all.
+func (t Timeline) CriticalPath() (CriticalPath, bool) {
+	n := len(t.Description)
+	if n < 2 || !t.IsComplete() {
+		return CriticalPath{}, false
+	}
+
+	path := CriticalPath{
+		Timeline:  t,
+		Durations: make(Durations, n),
+		Shares:    make([]float64, n),
+	}
+	first, previous := -1, -1
+	for i, entry := range t.Entries {
+		if entry.IsZero() {
+			continue
+		}
+		if previous >= 0 {
+			path.Durations[i] = entry.Timestamp.Sub(t.Entries[previous].Timestamp)
+		} else {
+			first = i
+		}
+		previous = i
+	}
+	if first < 0 {
+		return CriticalPath{}, false
+	}
+	path.Total = t.Entries[previous].Timestamp.Sub(t.Entries[first].Timestamp)
+	if path.Total <= 0 {
+		return CriticalPath{}, false
+	}
+	for i, duration := range path.Durations {
+		path.Shares[i] = float64(duration) / float64(path.Total)
+	}
+	return path, true
+}
+
+//Dominant returns the index of the TimelinePoint that accounts for the largest share of the Timeline's elapsed time
+func (c CriticalPath) Dominant() int {
+	dominant := 1
+	for i, share := range c.Shares {
+		if i > 0 && share > c.Shares[dominant] {
+			dominant = i
+		}
+	}
+	return dominant
+}
+
+//CriticalPaths is a collection of CriticalPath
+type CriticalPaths []CriticalPath
+
+//CriticalPaths computes the CriticalPath of every complete Timeline, sorted from fastest to slowest
+func (t Timelines) CriticalPaths() CriticalPaths {
+	paths := CriticalPaths{}
+	for _, timeline := range t {
+		if path, ok := timeline.CriticalPath(); ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Stable(byCriticalPathTotal(paths))
+	return paths
+}
+
+//Between returns the CriticalPaths whose Total lies between the lower and upper percentiles (0 <= lower < upper <= 100).
+//The CriticalPaths must be sorted from fastest to slowest.  At least one CriticalPath is returned if there are any.
+func (c CriticalPaths) Between(lower float64, upper float64) CriticalPaths {
+	n := float64(len(c))
+	start := int(math.Floor(lower / 100.0 * n))
+	end := int(math.Ceil(upper / 100.0 * n))
+	if end > len(c) {
+		end = len(c)
+	}
+	if start >= end {
+		start = end - 1
+	}
+	if start < 0 {
+		return CriticalPaths{}
+	}
+	return c[start:end]
+}
+
+//Summary aggregates the CriticalPaths over the passed-in TimelineDescription
+func (c CriticalPaths) Summary(name string, description TimelineDescription) CriticalPathSummary {
+	n := len(description)
+	summary := CriticalPathSummary{
+		Name:          name,
+		N:             len(c),
+		MeanShares:    make([]float64, n),
+		MeanDurations: make(Durations, n),
+		Dominant:      make([]int, n),
+	}
+	if len(c) == 0 {
+		return summary
+	}
+
+	total := time.Duration(0)
+	for _, path := range c {
+		total += path.Total
+		for i := range description {
+			summary.MeanShares[i] += path.Shares[i]
+			summary.MeanDurations[i] += path.Durations[i]
+		}
+		summary.Dominant[path.Dominant()]++
+	}
+	summary.MeanTotal = total / time.Duration(len(c))
+	for i := range description {
+		summary.MeanShares[i] /= float64(len(c))
+		summary.MeanDurations[i] /= time.Duration(len(c))
+	}
+	return summary
+}
+
+//CriticalPathSummary describes how, on average, the elapsed time of a set of Timelines is spread over the TimelinePoints
+//
+//Dominant[i] is the number of Timelines in which TimelinePoint i accounts for the largest share of the elapsed time.
+type CriticalPathSummary struct {
+	Name          string
+	N             int
+	MeanTotal     time.Duration
+	MeanShares    []float64
+	MeanDurations Durations
+	Dominant      []int
+}
+
+//CriticalPathReport compares the critical paths of the fastest and slowest Timelines
+//
+//Summaries holds the summaries of the fastest Quantile percent of the complete Timelines, of all of them, and of the slowest Quantile percent.
+type CriticalPathReport struct {
+	Description TimelineDescription
+	Quantile    float64
+	Summaries   []CriticalPathSummary
+}
+
+//CriticalPathReport summarizes the critical paths of the fastest and slowest quantile (in percent, e.g. 10) of the complete Timelines
+func (t Timelines) CriticalPathReport(quantile float64) CriticalPathReport {
+	description := t.Description()
+	paths := t.CriticalPaths()
+	return CriticalPathReport{
+		Description: description,
+		Quantile:    quantile,
+		Summaries: []CriticalPathSummary{
+			paths.Between(0, quantile).Summary(fmt.Sprintf("fastest %g%%", quantile), description),
+			paths.Summary("all", description),
+			paths.Between(100-quantile, 100).Summary(fmt.Sprintf("slowest %g%%", quantile), description),
+		},
+	}
+}
+
+//Fastest returns the summary of the fastest Timelines
+func (r CriticalPathReport) Fastest() CriticalPathSummary {
+	return r.Summaries[0]
+}
+
+//Slowest returns the summary of the slowest Timelines
+func (r CriticalPathReport) Slowest() CriticalPathSummary {
+	return r.Summaries[len(r.Summaries)-1]
+}
+
+//CriticalPathStage ranks a TimelinePoint by the share of the slowest Timelines' elapsed time it accounts for
+type CriticalPathStage struct {
+	Index        int
+	Name         string
+	SlowestShare float64
+	FastestShare float64
+	Dominant     int
+}
+
+//Ranking returns the TimelinePoints (bar the first, which starts the clock) ordered by the share of the slowest Timelines' elapsed time
+//they account for: the first stage is the one that dominates tail latency.
+func (r CriticalPathReport) Ranking() []CriticalPathStage {
+	fastest, slowest := r.Fastest(), r.Slowest()
+	stages := []CriticalPathStage{}
+	for i := 1; i < len(r.Description); i++ {
+		stages = append(stages, CriticalPathStage{
+			Index:        i,
+			Name:         r.Description[i].Name,
+			SlowestShare: slowest.MeanShares[i],
+			FastestShare: fastest.MeanShares[i],
+			Dominant:     slowest.Dominant[i],
+		})
+	}
+	sort.Stable(bySlowestShare(stages))
+	return stages
+}
+
+//String renders the mean share of each TimelinePoint for each summary, followed by the tail latency ranking
+func (r CriticalPathReport) String() string {
+	if len(r.Summaries) == 0 || r.Summaries[1].N == 0 {
+		return "no complete timelines"
+	}
+
+	header := []string{"", "n", "mean total"}
+	for _, point := range r.Description[1:] {
+		header = append(header, point.Name)
+	}
+	rows := []string{strings.Join(header, "\t")}
+	for _, summary := range r.Summaries {
+		row := []string{summary.Name, fmt.Sprintf("%d", summary.N), summary.MeanTotal.String()}
+		for i := 1; i < len(r.Description); i++ {
+			row = append(row, fmt.Sprintf("%.1f%% (%s)", summary.MeanShares[i]*100, summary.MeanDurations[i]))
+		}
+		rows = append(rows, strings.Join(row, "\t"))
+	}
+
+	slowest := r.Slowest()
+	rows = append(rows, "", fmt.Sprintf("Tail latency ranking (%s):", slowest.Name))
+	for rank, stage := range r.Ranking() {
+		rows = append(rows, fmt.Sprintf("%d. %s: %.1f%% of elapsed time (%s: %.1f%%, %+.1f points) - largest stage in %d/%d",
+			rank+1, stage.Name, stage.SlowestShare*100, r.Fastest().Name, stage.FastestShare*100,
+			(stage.SlowestShare-stage.FastestShare)*100, stage.Dominant, slowest.N))
+	}
+	return strings.Join(rows, "\n")
+}
+
+type byCriticalPathTotal CriticalPaths
+
+func (c byCriticalPathTotal) Len() int           { return len(c) }
+func (c byCriticalPathTotal) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byCriticalPathTotal) Less(i, j int) bool { return c[i].Total < c[j].Total }
+
+type bySlowestShare []CriticalPathStage
+
+func (s bySlowestShare) Len() int           { return len(s) }
+func (s bySlowestShare) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s bySlowestShare) Less(i, j int) bool { return s[i].SlowestShare > s[j].SlowestShare }
@@ -9,6 +9,7 @@ The dsl package has a number of different nouns:
 
 - Entry: Cicerone's representation of a log line
 - Entries: an ordered list of entries, can be filtered and grouped
+- EntryStream: entries produced one at a time, can be filtered, grouped and collected into Entries without loading an entire log into memory
 - GroupedEntries: a collection of Entries grouped by an arbitrary key
 - EntryPair: a set of two entries typically used to represent a period of time between two events of interest
 - EntryPairs: a collection of EntryPair.  From this one can generate:
@@ -17,8 +18,17 @@ The dsl package has a number of different nouns:
 - TimelineDescription: a collection of TimelinePoints used to construct a timeline
 - Timeline: combines a TimelineDescription with an Entries -- represents the timeline associated with a particular object flowing through the logs
 - Timelines: a pile of logs will have several timelines in them.  These are collected into a Timelines object.
+- CriticalPath: the share of a complete Timeline's elapsed time spent reaching each TimelinePoint.  CriticalPathReport compares the fastest and slowest Timelines
+- Attempt: annotates one of several lifecycles logged under the same key.  GroupedEntries.SplitAttempts starts a new attempt whenever a Matcher matches again
+- Lineage: learns which identifiers refer to the same object from entries that mention several of them, and resolves them to a single correlation key
+- Failures: the incomplete timelines in a Timelines, with the errors logged under their grouping keys.  These cluster into FailureModes
+- OpenMetrics: exports Timelines as OpenMetrics (Prometheus) text: per-TimelinePoint histograms and summaries, timeline counts and per-VM counters
+- Window: a wall-clock time window.  EntryPairs and Timelines compute DTStats per window over fixed or sliding Windows
+- TimeSeries: values in consecutive time buckets, e.g. the number of timelines in flight in each TimelinePoint segment over time
+- SessionNode: a node in the tree of nested lager sessions logged by a source, with its entries, errors, duration and child sessions
 - Matchers: matchers take an Entry and return a boolean
 - Getters: getters take an Entry and pull data out of it
+- Queries: ParseMatcher and ParseGetter compile a small text language into Matchers and Getters
 
 Using these nouns and their attendant verbs one can use Cicerone to quickly slice and dice a collection of log lines.  The resulting data can then be visualized with the viz package.
 */
@@ -7,19 +7,42 @@ import (
 )
 
 //DTStats bndles up statistics extracted from a collection of EntryPairs
+//
+//Durations holds the sorted durations of the sample - use Percentile to compute arbitrary percentiles.
+//Outliers holds the EntryPairs with durations above P99, slowest first.
 type DTStats struct {
 	Name      string
 	Min       time.Duration
 	Max       time.Duration
 	Mean      time.Duration
+	Median    time.Duration
+	P90       time.Duration
+	P95       time.Duration
+	P99       time.Duration
+	StdDev    time.Duration
 	N         int
 	MinWinner EntryPair
 	MaxWinner EntryPair
+	Outliers  EntryPairs
+	Durations Durations
+}
+
+//Percentile returns the pth percentile (0 <= p <= 100) of the sample
+func (d DTStats) Percentile(p float64) time.Duration {
+	return d.Durations.sortedPercentile(p)
 }
 
 //Printing out a DTStats is useful - it will emit the Annotation associated with the most extreme EntryPair outliers in the sample
 func (d DTStats) String() string {
 	s := fmt.Sprintf("[%d] %s (%s) < %s < %s (%s)", d.N, d.Min, d.MinWinner.Annotation, d.Mean, d.Max, d.MaxWinner.Annotation)
+	s += fmt.Sprintf("\n\tmedian: %s p90: %s p95: %s p99: %s stddev: %s", d.Median, d.P90, d.P95, d.P99, d.StdDev)
+	if len(d.Outliers) > 0 {
+		outliers := []string{}
+		for _, pair := range d.Outliers {
+			outliers = append(outliers, pair.String())
+		}
+		s += fmt.Sprintf("\n\toutliers (> p99): %s", strings.Join(outliers, ", "))
+	}
 	if d.Name != "" {
 		s = fmt.Sprintf("%s\n\t%s", d.Name, s)
 	}
@@ -2,6 +2,7 @@ package dsl
 
 import (
 	"math"
+	"sort"
 	"time"
 )
 
@@ -43,3 +44,66 @@ func (d Durations) CountInRange(min time.Duration, max time.Duration) int {
 	}
 	return count
 }
+
+//Sorted returns a sorted copy of the durations
+func (d Durations) Sorted() Durations {
+	sorted := make(Durations, len(d))
+	copy(sorted, d)
+	sort.Sort(sorted)
+	return sorted
+}
+
+//Total returns the sum of the durations
+func (d Durations) Total() time.Duration {
+	total := time.Duration(0)
+	for _, duration := range d {
+		total += duration
+	}
+	return total
+}
+
+//Mean returns the mean duration
+func (d Durations) Mean() time.Duration {
+	if len(d) == 0 {
+		return 0
+	}
+	return d.Total() / time.Duration(len(d))
+}
+
+//StdDev returns the (population) standard deviation of the durations
+func (d Durations) StdDev() time.Duration {
+	if len(d) == 0 {
+		return 0
+	}
+	mean := float64(d.Mean())
+	variance := 0.0
+	for _, duration := range d {
+		variance += (float64(duration) - mean) * (float64(duration) - mean)
+	}
+	return time.Duration(math.Sqrt(variance / float64(len(d))))
+}
+
+//Percentile returns the pth percentile (0 <= p <= 100) of the durations, interpolating linearly between the closest ranks
+func (d Durations) Percentile(p float64) time.Duration {
+	return d.Sorted().sortedPercentile(p)
+}
+
+//Median returns the 50th percentile of the durations
+func (d Durations) Median() time.Duration {
+	return d.Percentile(50)
+}
+
+func (d Durations) sortedPercentile(p float64) time.Duration {
+	if len(d) == 0 {
+		return 0
+	}
+	rank := math.Min(math.Max(p, 0), 100) / 100 * float64(len(d)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	fraction := rank - float64(lower)
+	return d[lower] + time.Duration(fraction*float64(d[upper]-d[lower]))
+}
+
+func (d Durations) Len() int           { return len(d) }
+func (d Durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d Durations) Less(i, j int) bool { return d[i] < d[j] }
@@ -20,7 +20,31 @@ func (e Entries) First(matcher Matcher) (Entry, bool) {
 }
 
 //Filter returns the list of Entries that match the passed-in Matcher
+//
+//Large lists of Entries are filtered in parallel (see Parallelism).  The order of the Entries is preserved.
 func (e Entries) Filter(matcher Matcher) Entries {
+	ranges := chunkRanges(len(e), minParallelEntries)
+	chunks := make([]Entries, len(ranges))
+	parallelize(len(ranges), func(i int) {
+		chunks[i] = e[ranges[i].start:ranges[i].end].filter(matcher)
+	})
+
+	if len(chunks) == 1 {
+		return chunks[0]
+	}
+
+	n := 0
+	for _, chunk := range chunks {
+		n += len(chunk)
+	}
+	filtered := make(Entries, 0, n)
+	for _, chunk := range chunks {
+		filtered = append(filtered, chunk...)
+	}
+	return filtered
+}
+
+func (e Entries) filter(matcher Matcher) Entries {
 	filtered := Entries{}
 	for _, entry := range e {
 		if matcher.Match(entry) {
@@ -32,21 +56,29 @@ func (e Entries) Filter(matcher Matcher) Entries {
 
 //ConstructTimeline takes a TimelineDescription and a Zeroth entry and returns a Timeline
 //The Zeroth entry is used to compute the starting time the Timeline
+//
+//Each TimelinePoint is associated with the Entry picked by its MatchPolicy.  TimelinePoints that have no matching Entry are
+//left as zero Entries and are skipped when squashing: each Entry is squashed relative to the closest preceding Entry that was found.
 func (e Entries) ConstructTimeline(description TimelineDescription, zeroEntry Entry) Timeline {
 	timeline := Timeline{
 		Description: description,
 		ZeroEntry:   zeroEntry,
 	}
 
+	matches := e.timelineMatches(description)
+
 	timeOffset := time.Duration(0)
+	previous := -1
 
 	for i, point := range description {
-		entry, _ := e.First(point.Matcher)
-		if i > 0 {
-			previousEntry, _ := e.First(description[i-1].Matcher)
-			duration := entry.Timestamp.Sub(previousEntry.Timestamp)
-			timeOffset -= time.Duration(float64(duration) * (1 - point.Squash))
-			entry.Timestamp = entry.Timestamp.Add(timeOffset)
+		entry := matches[i]
+		if !entry.IsZero() {
+			if previous >= 0 {
+				duration := entry.Timestamp.Sub(matches[previous].Timestamp)
+				timeOffset -= time.Duration(float64(duration) * (1 - point.Squash))
+				entry.Timestamp = entry.Timestamp.Add(timeOffset)
+			}
+			previous = i
 		}
 
 		timeline.Entries = append(timeline.Entries, entry)
@@ -55,17 +87,97 @@ func (e Entries) ConstructTimeline(description TimelineDescription, zeroEntry En
 	return timeline
 }
 
+//timelineMatches finds the Entry picked by each TimelinePoint's MatchPolicy
+//
+//FirstMatch, LastMatch and NthMatch are resolved in a single pass over the Entries (which stops early when only FirstMatch and NthMatch
+//points are involved).  FirstMatchAfterPrevious depends on the Entries picked for the preceding TimelinePoints so it is resolved afterwards, in order.
+func (e Entries) timelineMatches(description TimelineDescription) Entries {
+	matches := make(Entries, len(description))
+	counts := make([]int, len(description))
+	done := make([]bool, len(description))
+	remaining := 0
+	for i, point := range description {
+		if point.Policy == FirstMatchAfterPrevious {
+			done[i] = true
+		} else {
+			remaining++
+		}
+	}
+
+	for _, entry := range e {
+		if remaining == 0 {
+			break
+		}
+		for i, point := range description {
+			if done[i] || !point.Matcher.Match(entry) {
+				continue
+			}
+			counts[i]++
+			switch point.Policy {
+			case LastMatch:
+				matches[i] = entry
+			case NthMatch:
+				if counts[i] == point.nth() {
+					matches[i] = entry
+					done[i] = true
+					remaining--
+				}
+			default:
+				matches[i] = entry
+				done[i] = true
+				remaining--
+			}
+		}
+	}
+
+	previous := Entry{}
+	for i, point := range description {
+		if point.Policy == FirstMatchAfterPrevious {
+			matches[i], _ = e.firstAtOrAfter(point.Matcher, previous)
+		}
+		if !matches[i].IsZero() {
+			previous = matches[i]
+		}
+	}
+
+	return matches
+}
+
+//firstAtOrAfter returns the first Entry that matches the passed-in Matcher and does not occur before the passed-in Entry.
+//A zero Entry places no constraint on the timestamp.
+func (e Entries) firstAtOrAfter(matcher Matcher, previous Entry) (Entry, bool) {
+	for _, entry := range e {
+		if !previous.IsZero() && entry.Timestamp.Before(previous.Timestamp) {
+			continue
+		}
+		if matcher.Match(entry) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
 //GroupBy groups all Entries by the passed in Getter it returns a GroupedEntries object
 //The values returned by the Getter correpond to the Keys in the returned GroupedEntries object
+//
+//For large lists of Entries the Getter is evaluated in parallel (see Parallelism).  Keys and Entries are
+//still ordered by first appearance.
 func (e Entries) GroupBy(getter Getter) *GroupedEntries {
-	groups := NewGroupedEntries()
+	keys := make([]interface{}, len(e))
+	oks := make([]bool, len(e))
+	ranges := chunkRanges(len(e), minParallelEntries)
+	parallelize(len(ranges), func(i int) {
+		for j := ranges[i].start; j < ranges[i].end; j++ {
+			keys[j], oks[j] = getter.Get(e[j])
+		}
+	})
 
-	for _, entry := range e {
-		key, ok := getter.Get(entry)
-		if !ok {
+	groups := NewGroupedEntries()
+	for i, entry := range e {
+		if !oks[i] {
 			continue
 		}
-		groups.Append(key, entry)
+		groups.Append(keys[i], entry)
 	}
 
 	return groups
@@ -1,6 +1,7 @@
 package dsl
 
 import (
+	"sort"
 	"strings"
 	"time"
 )
@@ -34,18 +35,64 @@ func (e EntryPairs) DTStats() DTStats {
 			maxWinner = pair
 		}
 	}
+	if len(e) == 0 {
+		return DTStats{}
+	}
 	mean = mean / time.Duration(len(e))
 
+	durations := e.Durations()
+	sorted := durations.Sorted()
+	p99 := sorted.sortedPercentile(99)
+
+	outliers := e.FilterByDurationGreaterThan(p99)
+	sort.Sort(sort.Reverse(outliers))
+
 	return DTStats{
 		Min:       min,
 		Max:       max,
 		Mean:      mean,
+		Median:    sorted.sortedPercentile(50),
+		P90:       sorted.sortedPercentile(90),
+		P95:       sorted.sortedPercentile(95),
+		P99:       p99,
+		StdDev:    durations.StdDev(),
 		N:         len(e),
 		MinWinner: minWinner,
 		MaxWinner: maxWinner,
+		Outliers:  outliers,
+		Durations: sorted,
+	}
+}
+
+//FilterByPercentileRange returns a copy of the EntryPairs whose durations lie between the lower and upper percentiles (inclusive)
+//
+//	pairs.FilterByPercentileRange(0, 20)
+//
+//returns the fastest 20% of the pairs.
+func (e EntryPairs) FilterByPercentileRange(lower float64, upper float64) EntryPairs {
+	sorted := e.Durations().Sorted()
+	min := sorted.sortedPercentile(lower)
+	max := sorted.sortedPercentile(upper)
+
+	filteredPairs := EntryPairs{}
+	for _, pair := range e {
+		if min <= pair.DT() && pair.DT() <= max {
+			filteredPairs = append(filteredPairs, pair)
+		}
 	}
+
+	return filteredPairs
 }
 
+//Len returns the length of the EntryPairs slice
+func (e EntryPairs) Len() int { return len(e) }
+
+//Swap swaps two pairs in place
+func (e EntryPairs) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+
+//Less orders pairs by duration
+func (e EntryPairs) Less(i, j int) bool { return e[i].DT() < e[j].DT() }
+
 //Durations returns a Durations slice of time.Duration composed - it's a collection of the time intervals in the slice of EntryPairs
 func (e EntryPairs) Durations() Durations {
 	durations := Durations{}
@@ -0,0 +1,222 @@
+package dsl
+
+import (
+	"container/heap"
+	"io"
+)
+
+//EntryStream is a source of Entries that are produced one at a time, in order.
+//
+//Streams let you filter (and group) very large logs without ever holding all the Entries in memory:
+//
+//	stream.Filter(matcher).GroupBy(getter)
+//
+//only keeps the Entries that satisfy matcher around.
+//
+//A stream can only be consumed once.  Methods that consume the stream (First, GroupBy, Collect, Each, WriteLagerFormatTo) close it when they are done.
+type EntryStream struct {
+	next   func() (Entry, error)
+	close  func() error
+	closed bool
+}
+
+//NewEntryStream creates an EntryStream.
+//
+//next should return io.EOF once the stream is exhausted.  close (which may be nil) is called to release any underlying resources.
+func NewEntryStream(next func() (Entry, error), close func() error) *EntryStream {
+	return &EntryStream{
+		next:  next,
+		close: close,
+	}
+}
+
+//Stream returns an EntryStream that produces the Entries in the slice
+func (e Entries) Stream() *EntryStream {
+	i := 0
+	return NewEntryStream(func() (Entry, error) {
+		if i >= len(e) {
+			return Entry{}, io.EOF
+		}
+		i++
+		return e[i-1], nil
+	}, nil)
+}
+
+//Next returns the next Entry in the stream.  It returns io.EOF when the stream is exhausted.
+func (s *EntryStream) Next() (Entry, error) {
+	return s.next()
+}
+
+//Close releases any resources held by the stream.  It is safe to call Close more than once.
+func (s *EntryStream) Close() error {
+	if s.closed || s.close == nil {
+		return nil
+	}
+	s.closed = true
+	return s.close()
+}
+
+//Each calls f with every Entry in the stream.  Returning non-nil will cause the iterator to abort.
+func (s *EntryStream) Each(f func(Entry) error) error {
+	defer s.Close()
+	for {
+		entry, err := s.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = f(entry)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//Filter returns a stream of the Entries that match the passed-in Matcher.
+//Closing the returned stream closes the underlying stream.
+func (s *EntryStream) Filter(matcher Matcher) *EntryStream {
+	return NewEntryStream(func() (Entry, error) {
+		for {
+			entry, err := s.next()
+			if err != nil {
+				return Entry{}, err
+			}
+			if matcher.Match(entry) {
+				return entry, nil
+			}
+		}
+	}, s.Close)
+}
+
+//First returns the *first* Entry in the stream that satisfies the passed in Matcher.
+//The second return value tells the caller if an entry was found or not.
+func (s *EntryStream) First(matcher Matcher) (Entry, bool, error) {
+	defer s.Close()
+	for {
+		entry, err := s.next()
+		if err == io.EOF {
+			return Entry{}, false, nil
+		}
+		if err != nil {
+			return Entry{}, false, err
+		}
+		if matcher.Match(entry) {
+			return entry, true, nil
+		}
+	}
+}
+
+//GroupBy behaves like entries.GroupBy but consumes the stream
+func (s *EntryStream) GroupBy(getter Getter) (*GroupedEntries, error) {
+	groups := NewGroupedEntries()
+	err := s.Each(func(entry Entry) error {
+		key, ok := getter.Get(entry)
+		if ok {
+			groups.Append(key, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+//Collect reads the remainder of the stream into an Entries slice
+func (s *EntryStream) Collect() (Entries, error) {
+	entries := Entries{}
+	err := s.Each(func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+//WriteLagerFormatTo emits lager-formatted entries to the passed in writer as they are read off the stream
+func (s *EntryStream) WriteLagerFormatTo(w io.Writer) error {
+	return s.Each(func(entry Entry) error {
+		return entry.WriteLagerFormatTo(w)
+	})
+}
+
+//MergeEntryStreams merges streams that are each ordered by time into a single stream ordered by time.
+//Only one Entry per stream is held in memory at any time.
+//Closing the returned stream closes all the passed-in streams.
+//
+//If a stream fails, the Entry that was due to be returned is returned first and the error is returned by the following call.
+func MergeEntryStreams(streams ...*EntryStream) *EntryStream {
+	h := &entryStreamHeap{}
+	initialized := false
+	var pendingErr error
+
+	return NewEntryStream(func() (Entry, error) {
+		if !initialized {
+			initialized = true
+			for _, stream := range streams {
+				entry, err := stream.Next()
+				if err == io.EOF {
+					continue
+				}
+				if err != nil {
+					return Entry{}, err
+				}
+				heap.Push(h, entryStreamHead{entry, stream})
+			}
+		}
+
+		if pendingErr != nil {
+			err := pendingErr
+			pendingErr = nil
+			return Entry{}, err
+		}
+
+		if h.Len() == 0 {
+			return Entry{}, io.EOF
+		}
+
+		head := heap.Pop(h).(entryStreamHead)
+		next, err := head.stream.Next()
+		if err == nil {
+			heap.Push(h, entryStreamHead{next, head.stream})
+		} else if err != io.EOF {
+			pendingErr = err
+		}
+
+		return head.entry, nil
+	}, func() error {
+		var firstErr error
+		for _, stream := range streams {
+			if err := stream.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	})
+}
+
+// Merging (private)
+
+type entryStreamHead struct {
+	entry  Entry
+	stream *EntryStream
+}
+
+type entryStreamHeap []entryStreamHead
+
+func (h entryStreamHeap) Len() int { return len(h) }
+func (h entryStreamHeap) Less(i, j int) bool {
+	return h[i].entry.Timestamp.Before(h[j].entry.Timestamp)
+}
+func (h entryStreamHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *entryStreamHeap) Push(x interface{}) { *h = append(*h, x.(entryStreamHead)) }
+func (h *entryStreamHeap) Pop() interface{} {
+	old := *h
+	head := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return head
+}
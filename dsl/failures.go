@@ -0,0 +1,241 @@
+package dsl
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+)
+
+var failureGUIDRegExp = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+var failureAddressRegExp = regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}(:\d+)?\b`)
+var failureHexRegExp = regexp.MustCompile(`\b(0x[0-9a-fA-F]+|[0-9a-fA-F]{8,})\b`)
+var failureNumberRegExp = regexp.MustCompile(`\d+(\.\d+)?`)
+
+//NoErrorsLogged is the signature of failures that have no ERROR-level entries associated with them
+const NoErrorsLogged = "no errors logged"
+
+//Failure describes an incomplete Timeline
+//
+//LastPoint is the index of the last TimelinePoint the Timeline reached (-1 if it reached none).
+//FailedPoint is the index of the first required (i.e. not Optional) TimelinePoint the Timeline failed to reach.
+//Errors holds the ERROR-level entries (or entries carrying an Error) that share the Timeline's grouping key.
+//Signature is the normalized error message of the first of these (see NormalizeErrorMessage) and is used to cluster failures.
+type Failure struct {
+	Timeline    Timeline
+	LastPoint   int
+	FailedPoint int
+	Errors      Entries
+	Signature   string
+}
+
+//Stage returns the name of the first required TimelinePoint the Timeline failed to reach: this is where the failure occurred
+func (f Failure) Stage() string {
+	return f.Timeline.Description[f.FailedPoint].Name
+}
+
+//LastPointName returns the name of the last TimelinePoint the Timeline reached
+func (f Failure) LastPointName() string {
+	if f.LastPoint < 0 {
+		return "none"
+	}
+	return f.Timeline.Description[f.LastPoint].Name
+}
+
+//Failures is a collection of Failure
+type Failures []Failure
+
+//Failures builds a Failure for each incomplete Timeline in the passed-in Timelines.
+//
+//The Timelines must have been constructed from the GroupedEntries: the ERROR-level entries for each Timeline are
+//looked up using the Timeline's Annotation as the grouping key.
+func (g *GroupedEntries) Failures(timelines Timelines) Failures {
+	failures := Failures{}
+	for _, timeline := range timelines {
+		if timeline.IsComplete() {
+			continue
+		}
+
+		failure := Failure{
+			Timeline:    timeline,
+			LastPoint:   -1,
+			FailedPoint: -1,
+			Errors:      Entries{},
+			Signature:   NoErrorsLogged,
+		}
+		for i, entry := range timeline.Entries {
+			if !entry.IsZero() {
+				failure.LastPoint = i
+			} else if failure.FailedPoint < 0 && !timeline.Description[i].Optional {
+				failure.FailedPoint = i
+			}
+		}
+
+		entries, _ := g.Lookup(timeline.Annotation)
+		for _, entry := range entries {
+			if entry.LogLevel >= lager.ERROR || entry.Error != nil {
+				failure.Errors = append(failure.Errors, entry)
+			}
+		}
+		if len(failure.Errors) > 0 {
+			failure.Signature = NormalizeErrorMessage(failure.Errors[0])
+		}
+
+		failures = append(failures, failure)
+	}
+	return failures
+}
+
+//NormalizeErrorMessage returns the entry's message and error with GUIDs, addresses, hex strings and numbers
+//replaced by placeholders so that errors that differ only by the objects involved can be clustered together.
+func NormalizeErrorMessage(entry Entry) string {
+	message := entry.Message
+	if entry.Error != nil {
+		message += ": " + entry.Error.Error()
+	}
+	message = failureGUIDRegExp.ReplaceAllString(message, "<guid>")
+	message = failureAddressRegExp.ReplaceAllString(message, "<address>")
+	message = failureHexRegExp.ReplaceAllString(message, "<hex>")
+	message = failureNumberRegExp.ReplaceAllString(message, "<n>")
+	return message
+}
+
+//FailureMode is a cluster of Failures that share a Signature
+//
+//Stages counts the failures in the cluster by the stage at which they occurred.
+type FailureMode struct {
+	Signature string
+	Failures  Failures
+	Stages    map[string]int
+}
+
+//Example returns the first error entry associated with the failure mode (the zero Entry if no errors were logged)
+func (f FailureMode) Example() Entry {
+	for _, failure := range f.Failures {
+		if len(failure.Errors) > 0 {
+			return failure.Errors[0]
+		}
+	}
+	return Entry{}
+}
+
+//StageNames returns the stages at which the failure mode occurred, most common first
+func (f FailureMode) StageNames() []string {
+	names := []string{}
+	for name := range f.Stages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sort.Stable(byStageCount{names, f.Stages})
+	return names
+}
+
+//Annotations returns the Annotations of up to n of the Timelines in the failure mode
+func (f FailureMode) Annotations(n int) []string {
+	annotations := []string{}
+	for i := 0; i < len(f.Failures) && i < n; i++ {
+		annotations = append(annotations, fmt.Sprintf("%s", f.Failures[i].Timeline.Annotation))
+	}
+	return annotations
+}
+
+//FailureModes is a collection of FailureMode, most common first
+type FailureModes []FailureMode
+
+//Modes clusters the Failures by Signature.  The resulting FailureModes are sorted by the number of failures (most common first).
+func (f Failures) Modes() FailureModes {
+	lookup := map[string]int{}
+	modes := FailureModes{}
+	for _, failure := range f {
+		index, ok := lookup[failure.Signature]
+		if !ok {
+			modes = append(modes, FailureMode{
+				Signature: failure.Signature,
+				Stages:    map[string]int{},
+			})
+			index = len(modes) - 1
+			lookup[failure.Signature] = index
+		}
+		modes[index].Failures = append(modes[index].Failures, failure)
+		modes[index].Stages[failure.Stage()] += 1
+	}
+
+	sort.Stable(byFailureCount(modes))
+	return modes
+}
+
+//String renders a report of the failure modes: for each mode, the number of failures, the stages at which they occurred,
+//an example error (with its trace) and a few example annotations
+func (f FailureModes) String() string {
+	s := []string{}
+	for _, mode := range f {
+		stages := []string{}
+		for _, name := range mode.StageNames() {
+			stages = append(stages, fmt.Sprintf("%s (%d)", name, mode.Stages[name]))
+		}
+		s = append(s, fmt.Sprintf("[%d] %s", len(mode.Failures), mode.Signature))
+		s = append(s, fmt.Sprintf("\tstages: %s", strings.Join(stages, ", ")))
+
+		example := mode.Example()
+		if !example.IsZero() {
+			s = append(s, fmt.Sprintf("\texample: [%s] %s %s", example.VM(), example.Source, example.Message))
+			if example.Error != nil {
+				s = append(s, fmt.Sprintf("\terror: %s", example.Error.Error()))
+			}
+			if example.Trace != "" {
+				s = append(s, "\ttrace:\n\t\t"+strings.Replace(strings.TrimSpace(example.Trace), "\n", "\n\t\t", -1))
+			}
+		}
+		s = append(s, fmt.Sprintf("\tannotations: %s", strings.Join(mode.Annotations(5), ", ")))
+	}
+	return strings.Join(s, "\n")
+}
+
+//ToCSV generates a CSV file with one row per failure mode
+func (f FailureModes) ToCSV(w io.Writer) error {
+	csvWriter := csv.NewWriter(w)
+
+	csvWriter.Write([]string{"count", "signature", "stages", "example-error", "example-trace", "annotations"})
+
+	for _, mode := range f {
+		stages := []string{}
+		for _, name := range mode.StageNames() {
+			stages = append(stages, fmt.Sprintf("%s:%d", name, mode.Stages[name]))
+		}
+		example := mode.Example()
+		exampleError := ""
+		if example.Error != nil {
+			exampleError = example.Error.Error()
+		}
+		csvWriter.Write([]string{
+			fmt.Sprintf("%d", len(mode.Failures)),
+			mode.Signature,
+			strings.Join(stages, ";"),
+			exampleError,
+			example.Trace,
+			strings.Join(mode.Annotations(5), ";"),
+		})
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+type byFailureCount FailureModes
+
+func (f byFailureCount) Len() int           { return len(f) }
+func (f byFailureCount) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f byFailureCount) Less(i, j int) bool { return len(f[i].Failures) > len(f[j].Failures) }
+
+type byStageCount struct {
+	names  []string
+	counts map[string]int
+}
+
+func (s byStageCount) Len() int           { return len(s.names) }
+func (s byStageCount) Swap(i, j int)      { s.names[i], s.names[j] = s.names[j], s.names[i] }
+func (s byStageCount) Less(i, j int) bool { return s.counts[s.names[i]] > s.counts[s.names[j]] }
@@ -2,6 +2,8 @@ package dsl
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -53,6 +55,76 @@ var GetSession = GetterFunc(func(entry Entry) (interface{}, bool) {
 	return entry.Session, true
 })
 
+//GetTimestamp returns the timestamp (a time.Time) associated with an entry
+var GetTimestamp = GetterFunc(func(entry Entry) (interface{}, bool) {
+	return entry.Timestamp, true
+})
+
+//RegExpGetter takes a Getter (presumed to return a string) and a regular expression (encoded as a string)
+//RegExpGetter returns the first capture group of the regular expression in the string returned by the Getter, or the entire match if
+//the regular expression has no capture groups.  Entries whose string does not match are skipped.
+//
+//This is useful for components that embed identifiers in their messages instead of their lager.Data.  For example, garden logs
+//`garden-linux.pool.HANDLE.creating` so
+//
+//	entries.GroupBy(MessageRegExpGetter(`^garden-linux\.pool\.([^.]+)\.`))
+//
+//groups garden's pool entries by container handle in a single pass.
+func RegExpGetter(getter Getter, regExp string) Getter {
+	re := regexp.MustCompile(regExp)
+	return GetterFunc(func(entry Entry) (interface{}, bool) {
+		value, ok := getter.Get(entry)
+		if !ok {
+			return nil, false
+		}
+		stringValue, ok := value.(string)
+		if !ok {
+			return nil, false
+		}
+		match := re.FindStringSubmatch(stringValue)
+		if match == nil {
+			return nil, false
+		}
+		if len(match) > 1 {
+			return match[1], true
+		}
+		return match[0], true
+	})
+}
+
+//MessageRegExpGetter extracts the first capture group of the passed-in regular expression from the Entry's Message (see RegExpGetter)
+func MessageRegExpGetter(regExp string) Getter {
+	return RegExpGetter(GetMessage, regExp)
+}
+
+//MessageSegmentGetter returns the segment at the passed-in index of the Entry's Message, split on "." (lager separates sessions with ".").
+//Messages with too few segments are skipped.
+func MessageSegmentGetter(index int) Getter {
+	return GetterFunc(func(entry Entry) (interface{}, bool) {
+		segments := strings.Split(entry.Message, ".")
+		if index < 0 || index >= len(segments) {
+			return nil, false
+		}
+		return segments[index], true
+	})
+}
+
+//CompositeGetter returns a Getter that joins the values returned by all the passed-in Getters with "/" (e.g. a process-guid and an index).
+//Entries that are missing any of the values are skipped.
+func CompositeGetter(getters ...Getter) Getter {
+	return GetterFunc(func(entry Entry) (interface{}, bool) {
+		values := []string{}
+		for _, getter := range getters {
+			value, ok := getter.Get(entry)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, fmt.Sprintf("%v", value))
+		}
+		return strings.Join(values, "/"), true
+	})
+}
+
 //DataGetter returns a Getter that can extract data from an Entry's Data field
 //DataGetter takes multiple keys.  These are tried in order -- if a key is found in the Data field, the corresponding value is returned.
 //A key can be a full-blown JSON path (e.g. `foo.bar.baz`) -- DataGetter will traverse the Data field as far as possible to fetch the corresponding value.
@@ -81,24 +81,44 @@ func (g *GroupedEntries) EachGroup(f func(interface{}, Entries) error) error {
 //	entries.GroupBy(getter).Filter(matcher)
 //
 //are identical.
+//
+//Groups are filtered in parallel (see Parallelism).
 func (g *GroupedEntries) Filter(matcher Matcher) *GroupedEntries {
+	filtered := make([]Entries, len(g.Entries))
+	g.eachGroupInParallel(func(i int) {
+		filtered[i] = g.Entries[i].filter(matcher)
+	})
+
 	filteredGroups := NewGroupedEntries()
-	g.EachGroup(func(key interface{}, entries Entries) error {
-		filtered := entries.Filter(matcher)
-		if len(filtered) > 0 {
-			filteredGroups.AppendEntries(key, filtered)
+	for i, key := range g.Keys {
+		if len(filtered[i]) > 0 {
+			filteredGroups.AppendEntries(key, filtered[i])
 		}
-		return nil
-	})
+	}
 	return filteredGroups
 }
 
+//eachGroupInParallel calls f with the index of each group, spreading the groups across Parallelism workers
+func (g *GroupedEntries) eachGroupInParallel(f func(i int)) {
+	ranges := chunkRanges(len(g.Entries), minParallelGroups)
+	parallelize(len(ranges), func(i int) {
+		for j := ranges[i].start; j < ranges[i].end; j++ {
+			f(j)
+		}
+	})
+}
+
 func (g *GroupedEntries) first(matcher Matcher) (Entry, bool) {
-	firsts := make(Entries, 0, len(g.Entries))
+	candidates := make(Entries, len(g.Entries))
+	found := make([]bool, len(g.Entries))
+	g.eachGroupInParallel(func(i int) {
+		candidates[i], found[i] = g.Entries[i].First(matcher)
+	})
 
-	for _, entries := range g.Entries {
-		if fst, found := entries.First(matcher); found {
-			firsts = append(firsts, fst)
+	firsts := make(Entries, 0, len(g.Entries))
+	for i, candidate := range candidates {
+		if found[i] {
+			firsts = append(firsts, candidate)
 		}
 	}
 
@@ -114,19 +134,19 @@ func (g *GroupedEntries) first(matcher Matcher) (Entry, bool) {
 //The Key associated with the Entries element becomes the Annotation associated with the Timeline.
 //
 //Note that Timelines aren't Key=>Timeline mappings.  Instead GroupedEntries returns a *flat list* of Timelines with the Key parameter associated with the individual Timeline.
+//
+//Timelines are constructed in parallel (see Parallelism) but are returned in the order of the Keys.
 func (g *GroupedEntries) ConstructTimelines(description TimelineDescription) (Timelines, error) {
 	firstEntry, found := g.first(description[0].Matcher)
 	if !found {
 		return Timelines{}, fmt.Errorf("unable to find first entry to anchor timelines")
 	}
 
-	timelines := Timelines{}
+	timelines := make(Timelines, len(g.Keys))
 
-	g.EachGroup(func(key interface{}, entries Entries) error {
-		timeline := entries.ConstructTimeline(description, firstEntry)
-		timeline.Annotation = key
-		timelines = append(timelines, timeline)
-		return nil
+	g.eachGroupInParallel(func(i int) {
+		timelines[i] = g.Entries[i].ConstructTimeline(description, firstEntry)
+		timelines[i].Annotation = g.Keys[i]
 	})
 
 	return timelines, nil
@@ -0,0 +1,190 @@
+package dsl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//Lineage resolves the different identifiers an object is known by as it flows through several components to a single correlation key.
+//
+//Some hops merely rename an identifier (TaskGuid becomes Guid becomes Container.Handle) and are handled by DataGetter.  Others use
+//genuinely different identifiers (process-guid + index => instance-guid => container handle).  Lineage learns that two identifiers
+//refer to the same object whenever an Entry mentions both of them, and unions equivalent identifiers into a single lineage.
+//
+//The correlation key of a lineage is the identifier that was learned first - typically the identifier used by the component the
+//object enters the system through.  For example:
+//
+//	lineage := entries.Lineage(CompositeGetter(DataGetter("process-guid"), DataGetter("index")), DataGetter("instance-guid"), DataGetter("handle"))
+//	entries.GroupBy(lineage.Getter())
+//
+//Learning is not safe for concurrent use.  Once learning is done, Resolve and Getter are.
+type Lineage struct {
+	Identifiers []Getter
+
+	parent map[string]string
+	order  map[string]int
+}
+
+//NewLineage returns an empty Lineage over the passed-in identifier Getters
+func NewLineage(identifiers ...Getter) *Lineage {
+	return &Lineage{
+		Identifiers: identifiers,
+		parent:      map[string]string{},
+		order:       map[string]int{},
+	}
+}
+
+//Lineage learns the lineage of the passed-in identifiers from the Entries
+func (e Entries) Lineage(identifiers ...Getter) *Lineage {
+	lineage := NewLineage(identifiers...)
+	lineage.Learn(e)
+	return lineage
+}
+
+//Learn links the identifiers mentioned together by each of the Entries
+func (l *Lineage) Learn(entries Entries) {
+	for _, entry := range entries {
+		l.LearnEntry(entry)
+	}
+}
+
+//LearnEntry links the identifiers mentioned together by the Entry.  Use it to learn from an EntryStream.
+func (l *Lineage) LearnEntry(entry Entry) {
+	ids := l.identifiers(entry)
+	for _, id := range ids {
+		l.add(id)
+	}
+	for i := 1; i < len(ids); i++ {
+		l.Link(ids[0], ids[i])
+	}
+}
+
+//Link records that the two identifiers refer to the same object
+func (l *Lineage) Link(a string, b string) {
+	l.add(a)
+	l.add(b)
+	rootA, rootB := l.compress(a), l.compress(b)
+	if rootA == rootB {
+		return
+	}
+	if l.order[rootB] < l.order[rootA] {
+		rootA, rootB = rootB, rootA
+	}
+	l.parent[rootB] = rootA
+}
+
+//Resolve returns the correlation key of the passed-in identifier.  Identifiers that were never learned are their own correlation key.
+func (l *Lineage) Resolve(id string) string {
+	for {
+		parent, ok := l.parent[id]
+		if !ok || parent == id {
+			return id
+		}
+		id = parent
+	}
+}
+
+//Getter returns a Getter that returns the correlation key of the first identifier the Entry mentions
+func (l *Lineage) Getter() Getter {
+	return GetterFunc(func(entry Entry) (interface{}, bool) {
+		for _, identifier := range l.Identifiers {
+			if id, ok := lineageIdentifier(identifier, entry); ok {
+				return l.Resolve(id), true
+			}
+		}
+		return nil, false
+	})
+}
+
+//Aliases returns the correlation key of each lineage along with all the identifiers in the lineage, in the order in which they were learned
+func (l *Lineage) Aliases() map[string][]string {
+	ids := []string{}
+	for id := range l.order {
+		ids = append(ids, id)
+	}
+	sort.Sort(byLineageOrder{ids, l.order})
+
+	aliases := map[string][]string{}
+	for _, id := range ids {
+		key := l.Resolve(id)
+		aliases[key] = append(aliases[key], id)
+	}
+	return aliases
+}
+
+//Largest returns the identifiers of the lineage that links the most identifiers.  An unexpectedly large lineage means that
+//unrelated objects share an identifier and have been merged into one.
+func (l *Lineage) Largest() []string {
+	largest := []string{}
+	for _, ids := range l.Aliases() {
+		if len(ids) > len(largest) || (len(ids) == len(largest) && len(ids) > 0 && l.order[ids[0]] < l.order[largest[0]]) {
+			largest = ids
+		}
+	}
+	return largest
+}
+
+//String lists the lineages that link more than one identifier
+func (l *Lineage) String() string {
+	aliases := l.Aliases()
+	keys := []string{}
+	for key, ids := range aliases {
+		if len(ids) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Sort(byLineageOrder{keys, l.order})
+
+	lines := []string{}
+	for _, key := range keys {
+		lines = append(lines, strings.Join(aliases[key], " => "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (l *Lineage) identifiers(entry Entry) []string {
+	ids := []string{}
+	for _, identifier := range l.Identifiers {
+		if id, ok := lineageIdentifier(identifier, entry); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (l *Lineage) add(id string) {
+	if _, ok := l.order[id]; !ok {
+		l.order[id] = len(l.order)
+		l.parent[id] = id
+	}
+}
+
+//compress finds the root of id's lineage and points every identifier along the way directly at the root
+func (l *Lineage) compress(id string) string {
+	root := l.Resolve(id)
+	for id != root {
+		next := l.parent[id]
+		l.parent[id] = root
+		id = next
+	}
+	return root
+}
+
+func lineageIdentifier(getter Getter, entry Entry) (string, bool) {
+	value, ok := getter.Get(entry)
+	if !ok || value == nil {
+		return "", false
+	}
+	id := fmt.Sprintf("%v", value)
+	return id, id != ""
+}
+
+type byLineageOrder struct {
+	ids   []string
+	order map[string]int
+}
+
+func (b byLineageOrder) Len() int           { return len(b.ids) }
+func (b byLineageOrder) Swap(i, j int)      { b.ids[i], b.ids[j] = b.ids[j], b.ids[i] }
+func (b byLineageOrder) Less(i, j int) bool { return b.order[b.ids[i]] < b.order[b.ids[j]] }
@@ -0,0 +1,74 @@
+package dsl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pivotal-golang/lager"
+)
+
+func TestLineageMergesTransitively(t *testing.T) {
+	lineage := NewLineage()
+	lineage.Link("A", "B")
+	lineage.Link("C", "B")
+
+	for _, id := range []string{"A", "B", "C"} {
+		if key := lineage.Resolve(id); key != "A" {
+			t.Errorf("expected %s to resolve to A, got %s", id, key)
+		}
+	}
+	if largest := lineage.Largest(); !reflect.DeepEqual(largest, []string{"A", "B", "C"}) {
+		t.Errorf("expected the largest lineage to be A => B => C, got %v", largest)
+	}
+}
+
+func TestLineageKeepsTheEarliestLearnedRootWhenMergingLineages(t *testing.T) {
+	lineage := NewLineage()
+	lineage.Link("A", "B")
+	lineage.Link("C", "D")
+	lineage.Link("D", "B")
+
+	for _, id := range []string{"A", "B", "C", "D"} {
+		if key := lineage.Resolve(id); key != "A" {
+			t.Errorf("expected %s to resolve to A, got %s", id, key)
+		}
+	}
+	if aliases := lineage.Aliases(); !reflect.DeepEqual(aliases, map[string][]string{"A": {"A", "B", "C", "D"}}) {
+		t.Errorf("expected a single lineage, got %v", aliases)
+	}
+}
+
+func TestLineageGetter(t *testing.T) {
+	entries := Entries{
+		newTestEntry("bbs", 1, lager.Data{"process-guid": "pg", "index": 0.0}),
+		newTestEntry("auctioneer", 2, lager.Data{"process-guid": "pg", "index": 0.0, "instance-guid": "ig"}),
+		newTestEntry("executor", 3, lager.Data{"instance-guid": "ig", "handle": "ig"}),
+		newTestEntry("garden", 4, lager.Data{"handle": "ig"}),
+		newTestEntry("stranger", 5, lager.Data{"handle": "never-linked"}),
+		newTestEntry("nobody", 6, lager.Data{}),
+	}
+
+	lineage := entries.Lineage(CompositeGetter(DataGetter("process-guid"), DataGetter("index")), DataGetter("instance-guid"), DataGetter("handle"))
+	getter := lineage.Getter()
+
+	root, ok := getter.Get(entries[0])
+	if !ok {
+		t.Fatalf("expected the first entry to have a correlation key")
+	}
+	for _, entry := range entries[1:4] {
+		if key, ok := getter.Get(entry); !ok || key != root {
+			t.Errorf("expected %s to resolve to %v, got %v", entry.Message, root, key)
+		}
+	}
+	if key, ok := getter.Get(entries[4]); !ok || key != "never-linked" {
+		t.Errorf("expected an identifier that was never linked to be its own key, got %v", key)
+	}
+	if _, ok := getter.Get(entries[5]); ok {
+		t.Errorf("expected an entry without identifiers to have no key")
+	}
+
+	unlearned := newTestEntry("late", 7, lager.Data{"handle": "never-learned"})
+	if key, ok := getter.Get(unlearned); !ok || key != "never-learned" {
+		t.Errorf("expected an identifier that was never learned to be its own key, got %v", key)
+	}
+}
@@ -0,0 +1,74 @@
+package dsl
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+//MannWhitney performs a two-sided Mann-Whitney U test comparing two samples of durations.
+//
+//The test is non-parametric - it makes no assumptions about the shape of the distributions - which suits the long-tailed durations found in logs.
+//U is the U statistic for the receiver, Z its normal approximation (corrected for ties) and P the two-sided p-value.
+//A small P (say, < 0.05) means the two samples are unlikely to come from the same distribution.
+//
+//P is 1 if either sample is empty.
+func (d Durations) MannWhitney(other Durations) (U float64, Z float64, P float64) {
+	n1 := float64(len(d))
+	n2 := float64(len(other))
+	if n1 == 0 || n2 == 0 {
+		return 0, 0, 1
+	}
+
+	samples := make([]rankedSample, 0, len(d)+len(other))
+	for _, duration := range d {
+		samples = append(samples, rankedSample{duration: duration, first: true})
+	}
+	for _, duration := range other {
+		samples = append(samples, rankedSample{duration: duration})
+	}
+	sort.Sort(rankedSamples(samples))
+
+	//assign ranks, averaging over ties
+	rankSum := 0.0
+	tieCorrection := 0.0
+	for i := 0; i < len(samples); {
+		j := i
+		for j < len(samples) && samples[j].duration == samples[i].duration {
+			j++
+		}
+		averageRank := float64(i+j+1) / 2.0
+		for k := i; k < j; k++ {
+			if samples[k].first {
+				rankSum += averageRank
+			}
+		}
+		t := float64(j - i)
+		tieCorrection += t*t*t - t
+		i = j
+	}
+
+	n := n1 + n2
+	U = rankSum - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+	if variance <= 0 {
+		return U, 0, 1
+	}
+
+	Z = (U - mean) / math.Sqrt(variance)
+	P = math.Erfc(math.Abs(Z) / math.Sqrt2)
+
+	return U, Z, P
+}
+
+type rankedSample struct {
+	duration time.Duration
+	first    bool
+}
+
+type rankedSamples []rankedSample
+
+func (r rankedSamples) Len() int           { return len(r) }
+func (r rankedSamples) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r rankedSamples) Less(i, j int) bool { return r[i].duration < r[j].duration }
@@ -0,0 +1,277 @@
+package dsl
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//DefaultOpenMetricsBuckets are the upper bounds (in seconds) of the duration histogram buckets
+var DefaultOpenMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+//DefaultOpenMetricsQuantiles are the quantiles reported by the duration summaries
+var DefaultOpenMetricsQuantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+var openMetricsInvalidNameRegExp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+//OpenMetrics accumulates metrics derived from Timelines and writes them in the OpenMetrics text exposition format.
+//
+//For every set of Timelines added it computes:
+//
+//	NAMESPACE_timeline_point_duration_seconds          - histogram of the duration of each TimelinePoint (see Timelines.EntryPairs)
+//	NAMESPACE_timeline_point_duration_summary_seconds  - summary (Quantiles) of the same durations
+//	NAMESPACE_timelines_total                          - the number of complete and incomplete timelines
+//	NAMESPACE_vm_timeline_points_total                 - the number of entries each VM contributed to each TimelinePoint
+//
+//Durations are labelled with the TimelinePoint and with the Job of the entry that ends the TimelinePoint.
+//The labels passed to AddTimelines (e.g. the grouping key of GroupedTimelines) are added to every sample.
+//
+//The output follows the OpenMetrics text format (counter families are named without their _total suffix and the
+//exposition ends with # EOF).  Set Timestamp to stamp every sample, e.g. for backfilling a past run into Prometheus.
+type OpenMetrics struct {
+	Namespace string
+	Buckets   []float64
+	Quantiles []float64
+	Timestamp time.Time
+
+	sets []openMetricsSet
+}
+
+type openMetricsSet struct {
+	labels    openMetricsLabels
+	timelines Timelines
+}
+
+type openMetricsLabel struct {
+	Name  string
+	Value string
+}
+
+type openMetricsLabels []openMetricsLabel
+
+func NewOpenMetrics(namespace string) *OpenMetrics {
+	return &OpenMetrics{
+		Namespace: openMetricsName(namespace),
+		Buckets:   DefaultOpenMetricsBuckets,
+		Quantiles: DefaultOpenMetricsQuantiles,
+	}
+}
+
+//openMetricsSampleLabels are the label names OpenMetrics adds to individual samples
+var openMetricsSampleLabels = map[string]bool{"point": true, "job": true, "vm": true, "index": true, "le": true, "quantile": true, "state": true}
+
+//AddTimelines adds the metrics for the passed-in Timelines.  Every sample is labelled with the passed-in labels.
+//
+//Labels named after one of the labels OpenMetrics adds to samples (point, job, vm, index, le, quantile and state) are prefixed
+//with group_ (e.g. group_job) so that no sample carries the same label twice.
+func (o *OpenMetrics) AddTimelines(timelines Timelines, labels map[string]string) {
+	if len(timelines) == 0 {
+		return
+	}
+	set := openMetricsSet{timelines: timelines}
+	for name, value := range labels {
+		name = openMetricsName(name)
+		if openMetricsSampleLabels[name] {
+			name = "group_" + name
+		}
+		set.labels = append(set.labels, openMetricsLabel{name, value})
+	}
+	sort.Sort(byLabelName(set.labels))
+	o.sets = append(o.sets, set)
+}
+
+//AddGroupedTimelines adds the metrics for each group of Timelines, labelling them with the group's key
+func (o *OpenMetrics) AddGroupedTimelines(label string, grouped *GroupedTimelines) {
+	grouped.EachGroup(func(key interface{}, timelines Timelines) error {
+		o.AddTimelines(timelines, map[string]string{label: fmt.Sprintf("%v", key)})
+		return nil
+	})
+}
+
+//Write writes the accumulated metrics in the OpenMetrics text exposition format
+func (o *OpenMetrics) Write(w io.Writer) error {
+	lines := []string{}
+	lines = append(lines, o.durationHistograms()...)
+	lines = append(lines, o.durationSummaries()...)
+	lines = append(lines, o.timelineCounters()...)
+	lines = append(lines, o.vmCounters()...)
+	lines = append(lines, "# EOF")
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+//durationsByJob collects the durations of the TimelinePoint at index, keyed by the job of the entry that ends the TimelinePoint
+func (o *OpenMetrics) durationsByJob(timelines Timelines, index int) ([]string, map[string]Durations) {
+	durations := map[string]Durations{}
+	for _, pair := range timelines.EntryPairs(index) {
+		durations[pair.SecondEntry.Job] = append(durations[pair.SecondEntry.Job], pair.DT())
+	}
+	jobs := []string{}
+	for job := range durations {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+	return jobs, durations
+}
+
+func (o *OpenMetrics) durationHistograms() []string {
+	name := o.Namespace + "_timeline_point_duration_seconds"
+	lines := []string{
+		"# TYPE " + name + " histogram",
+		"# HELP " + name + " Time taken to reach each TimelinePoint from the previous one.",
+	}
+	for _, set := range o.sets {
+		for i, point := range set.timelines.Description() {
+			jobs, durationsByJob := o.durationsByJob(set.timelines, i)
+			for _, job := range jobs {
+				durations := durationsByJob[job]
+				labels := set.labels.with("point", point.Name).with("job", job)
+				for _, bucket := range o.Buckets {
+					count := 0
+					for _, duration := range durations {
+						if duration.Seconds() <= bucket {
+							count++
+						}
+					}
+					lines = append(lines, o.sample(name+"_bucket", labels.with("le", openMetricsFloat(bucket)), float64(count)))
+				}
+				lines = append(lines, o.sample(name+"_bucket", labels.with("le", "+Inf"), float64(len(durations))))
+				lines = append(lines, o.sample(name+"_count", labels, float64(len(durations))))
+				lines = append(lines, o.sample(name+"_sum", labels, durations.Total().Seconds()))
+			}
+		}
+	}
+	return lines
+}
+
+func (o *OpenMetrics) durationSummaries() []string {
+	name := o.Namespace + "_timeline_point_duration_summary_seconds"
+	lines := []string{
+		"# TYPE " + name + " summary",
+		"# HELP " + name + " Quantiles of the time taken to reach each TimelinePoint from the previous one.",
+	}
+	for _, set := range o.sets {
+		for i, point := range set.timelines.Description() {
+			jobs, durationsByJob := o.durationsByJob(set.timelines, i)
+			for _, job := range jobs {
+				durations := durationsByJob[job].Sorted()
+				labels := set.labels.with("point", point.Name).with("job", job)
+				for _, quantile := range o.Quantiles {
+					lines = append(lines, o.sample(name, labels.with("quantile", openMetricsFloat(quantile)), durations.sortedPercentile(quantile*100).Seconds()))
+				}
+				lines = append(lines, o.sample(name+"_count", labels, float64(len(durations))))
+				lines = append(lines, o.sample(name+"_sum", labels, durations.Total().Seconds()))
+			}
+		}
+	}
+	return lines
+}
+
+func (o *OpenMetrics) timelineCounters() []string {
+	name := o.Namespace + "_timelines"
+	lines := []string{
+		"# TYPE " + name + " counter",
+		"# HELP " + name + " Number of complete and incomplete timelines.",
+	}
+	for _, set := range o.sets {
+		complete := 0
+		for _, timeline := range set.timelines {
+			if timeline.IsComplete() {
+				complete++
+			}
+		}
+		lines = append(lines, o.sample(name+"_total", set.labels.with("state", "complete"), float64(complete)))
+		lines = append(lines, o.sample(name+"_total", set.labels.with("state", "incomplete"), float64(len(set.timelines)-complete)))
+	}
+	return lines
+}
+
+func (o *OpenMetrics) vmCounters() []string {
+	name := o.Namespace + "_vm_timeline_points"
+	lines := []string{
+		"# TYPE " + name + " counter",
+		"# HELP " + name + " Number of entries each VM contributed to each TimelinePoint.",
+	}
+	for _, set := range o.sets {
+		for i, point := range set.timelines.Description() {
+			counts := map[string]int{}
+			vms := Entries{}
+			for _, timeline := range set.timelines {
+				entry := timeline.Entries[i]
+				if entry.IsZero() {
+					continue
+				}
+				if counts[entry.VM()] == 0 {
+					vms = append(vms, Entry{Job: entry.Job, Index: entry.Index})
+				}
+				counts[entry.VM()]++
+			}
+			sort.Sort(byVM(vms))
+			for _, vm := range vms {
+				labels := set.labels.with("point", point.Name).with("vm", vm.VM()).with("job", vm.Job).with("index", strconv.Itoa(vm.Index))
+				lines = append(lines, o.sample(name+"_total", labels, float64(counts[vm.VM()])))
+			}
+		}
+	}
+	return lines
+}
+
+func (o *OpenMetrics) sample(name string, labels openMetricsLabels, value float64) string {
+	line := name + labels.String() + " " + openMetricsFloat(value)
+	if !o.Timestamp.IsZero() {
+		line += " " + openMetricsFloat(float64(o.Timestamp.UnixNano())/float64(time.Second))
+	}
+	return line
+}
+
+func (l openMetricsLabels) with(name string, value string) openMetricsLabels {
+	labels := make(openMetricsLabels, len(l), len(l)+1)
+	copy(labels, l)
+	return append(labels, openMetricsLabel{name, value})
+}
+
+func (l openMetricsLabels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+	pairs := []string{}
+	for _, label := range l {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, label.Name, openMetricsLabelEscaper.Replace(label.Value)))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var openMetricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func openMetricsName(name string) string {
+	name = openMetricsInvalidNameRegExp.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+func openMetricsFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+type byLabelName openMetricsLabels
+
+func (l byLabelName) Len() int           { return len(l) }
+func (l byLabelName) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
+func (l byLabelName) Less(i, j int) bool { return l[i].Name < l[j].Name }
+
+type byVM Entries
+
+func (e byVM) Len() int           { return len(e) }
+func (e byVM) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e byVM) Less(i, j int) bool { return e[i].VM() < e[j].VM() }
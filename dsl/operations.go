@@ -0,0 +1,120 @@
+package dsl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//SpanConvention describes how a component logs the beginning and the end of an operation.
+//
+//Lager messages look like SOURCE.OPERATION.ACTION (e.g. rep.auction-perform-work.handling).
+//An entry whose ACTION is Start begins a span of OPERATION, the next entry in the same session
+//for the same OPERATION whose ACTION is one of Ends finishes it.
+type SpanConvention struct {
+	Start string
+	Ends  []string
+}
+
+//DefaultSpanConventions are the conventions followed by the Diego components
+var DefaultSpanConventions = []SpanConvention{
+	{"starting", []string{"finished", "succeeded", "failed", "complete", "completed", "done"}},
+	{"started", []string{"finished", "succeeded", "failed", "complete", "completed", "done"}},
+	{"handling", []string{"success", "succeeded", "failed", "done"}},
+}
+
+//Operations maps operation names (e.g. rep.auction-perform-work) to the EntryPairs that span each execution of the operation
+type Operations map[string]EntryPairs
+
+//Operations extracts spans from the Entries by pairing start-like messages with their terminal counterparts (see SpanConvention).
+//
+//Spans never cross sessions: entries are grouped by source, VM and session before pairing.  Within a session
+//repeated executions of an operation are paired in order.  Starts without a matching end are dropped.
+//
+//DefaultSpanConventions are used if no conventions are passed in.
+//The Annotation associated with each EntryPair is the VM and session the operation ran in.
+func (e Entries) Operations(conventions ...SpanConvention) Operations {
+	if len(conventions) == 0 {
+		conventions = DefaultSpanConventions
+	}
+
+	starts := map[string]bool{}
+	ends := map[string][]string{}
+	for _, convention := range conventions {
+		starts[convention.Start] = true
+		for _, end := range convention.Ends {
+			ends[end] = append(ends[end], convention.Start)
+		}
+	}
+
+	sorted := make(Entries, len(e))
+	copy(sorted, e)
+	sort.Stable(sorted)
+
+	operations := Operations{}
+	sorted.GroupBy(operationSessionGetter).EachGroup(func(key interface{}, entries Entries) error {
+		pending := map[string]Entries{}
+		for _, entry := range entries {
+			i := strings.LastIndex(entry.Message, ".")
+			if i < 0 {
+				continue
+			}
+			operation, action := entry.Message[:i], entry.Message[i+1:]
+
+			if starts[action] {
+				pending[operation+"."+action] = append(pending[operation+"."+action], entry)
+			}
+
+			for _, start := range ends[action] {
+				startEntries := pending[operation+"."+start]
+				if len(startEntries) == 0 {
+					continue
+				}
+				pending[operation+"."+start] = startEntries[1:]
+				operations[operation] = append(operations[operation], EntryPair{
+					FirstEntry:  startEntries[0],
+					SecondEntry: entry,
+					Annotation:  fmt.Sprintf("%s [%s]", entry.VM(), entry.Session),
+				})
+				break
+			}
+		}
+		return nil
+	})
+
+	return operations
+}
+
+var operationSessionGetter = GetterFunc(func(entry Entry) (interface{}, bool) {
+	return fmt.Sprintf("%s|%s|%s", entry.Source, entry.VM(), entry.Session), true
+})
+
+//Names returns the names of all the operations, sorted alphabetically
+func (o Operations) Names() []string {
+	names := []string{}
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//DTStatsSlice returns the DTStats for each operation, sorted by the total time consumed by the operation (most expensive first)
+func (o Operations) DTStatsSlice() DTStatsSlice {
+	dtStats := DTStatsSlice{}
+	for _, name := range o.Names() {
+		stats := o[name].DTStats()
+		stats.Name = name
+		dtStats = append(dtStats, stats)
+	}
+	sort.Stable(byTotalTime(dtStats))
+	return dtStats
+}
+
+type byTotalTime DTStatsSlice
+
+func (s byTotalTime) Len() int      { return len(s) }
+func (s byTotalTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byTotalTime) Less(i, j int) bool {
+	return s[i].Durations.Total() > s[j].Durations.Total()
+}
@@ -0,0 +1,81 @@
+package dsl
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/cloudfoundry/gunk/workpool"
+)
+
+//Parallelism is the number of workers used by Entries.Filter, Entries.GroupBy, GroupedEntries.Filter and
+//GroupedEntries.ConstructTimelines.  Set it to 1 to run everything on the calling goroutine.
+//
+//Matchers and Getters are called concurrently and must therefore be safe for concurrent use (all the Matchers
+//and Getters in this package are).  Results are always assembled in order so output is deterministic regardless of Parallelism.
+var Parallelism = runtime.NumCPU()
+
+//minParallelEntries and minParallelGroups are the smallest amounts of work worth handing off to a worker
+const minParallelEntries = 2048
+const minParallelGroups = 16
+
+type chunkRange struct {
+	start int
+	end   int
+}
+
+//chunkRanges splits [0, n) into contiguous ranges of at least minChunk elements.
+//There are a few more ranges than workers so that uneven chunks balance out.
+func chunkRanges(n int, minChunk int) []chunkRange {
+	chunks := Parallelism * 4
+	if n/minChunk < chunks {
+		chunks = n / minChunk
+	}
+	if chunks < 1 {
+		chunks = 1
+	}
+
+	size := (n + chunks - 1) / chunks
+	ranges := []chunkRange{}
+	for start := 0; start < n || len(ranges) == 0; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		ranges = append(ranges, chunkRange{start, end})
+	}
+	return ranges
+}
+
+//parallelize calls f(0) ... f(count-1) on a pool of Parallelism workers and returns when all calls are done
+func parallelize(count int, f func(i int)) {
+	if count == 1 || Parallelism <= 1 {
+		for i := 0; i < count; i++ {
+			f(i)
+		}
+		return
+	}
+
+	workers := Parallelism
+	if count < workers {
+		workers = count
+	}
+	pool, err := workpool.NewWorkPool(workers)
+	if err != nil {
+		for i := 0; i < count; i++ {
+			f(i)
+		}
+		return
+	}
+	defer pool.Stop()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(count)
+	for i := 0; i < count; i++ {
+		i := i
+		pool.Submit(func() {
+			defer wg.Done()
+			f(i)
+		})
+	}
+	wg.Wait()
+}
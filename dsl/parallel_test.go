@@ -0,0 +1,83 @@
+package dsl
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotal-golang/lager/chug"
+)
+
+func newTestEntry(message string, seconds float64, data lager.Data) Entry {
+	return Entry{
+		LogEntry: chug.LogEntry{
+			Timestamp: time.Unix(0, int64(seconds*float64(time.Second))),
+			Source:    "test",
+			Message:   message,
+			Data:      data,
+		},
+	}
+}
+
+func withParallelism(parallelism int, f func()) {
+	original := Parallelism
+	Parallelism = parallelism
+	defer func() { Parallelism = original }()
+	f()
+}
+
+func TestParallelismDoesNotChangeResults(t *testing.T) {
+	entries := Entries{}
+	messages := []string{"start", "noise", "middle", "noise", "end"}
+	for i := 0; i < 4*minParallelEntries; i++ {
+		guid := fmt.Sprintf("guid-%d", (i*7)%(2*minParallelGroups+5))
+		if i%97 == 0 {
+			continue
+		}
+		entries = append(entries, newTestEntry(messages[i%len(messages)], float64(i), lager.Data{"guid": guid}))
+	}
+
+	description := TimelineDescription{
+		{Name: "start", Matcher: MatchMessage("start")},
+		{Name: "middle", Matcher: MatchMessage("middle"), Policy: LastMatch},
+		{Name: "end", Matcher: MatchMessage("end"), Policy: FirstMatchAfterPrevious},
+	}
+
+	run := func() (Entries, *GroupedEntries, Timelines) {
+		filtered := entries.Filter(MatchMessage("start|middle|end"))
+		grouped := filtered.GroupBy(DataGetter("guid"))
+		timelines, err := grouped.ConstructTimelines(description)
+		if err != nil {
+			t.Fatalf("failed to construct timelines: %s", err)
+		}
+		return filtered, grouped, timelines
+	}
+
+	var serialFiltered, parallelFiltered Entries
+	var serialGrouped, parallelGrouped *GroupedEntries
+	var serialTimelines, parallelTimelines Timelines
+	withParallelism(1, func() { serialFiltered, serialGrouped, serialTimelines = run() })
+	withParallelism(8, func() { parallelFiltered, parallelGrouped, parallelTimelines = run() })
+
+	if !reflect.DeepEqual(serialFiltered, parallelFiltered) {
+		t.Errorf("Filter differs: %d serial entries, %d parallel entries", len(serialFiltered), len(parallelFiltered))
+	}
+	if !reflect.DeepEqual(serialGrouped.Keys, parallelGrouped.Keys) || !reflect.DeepEqual(serialGrouped.Entries, parallelGrouped.Entries) {
+		t.Errorf("GroupBy differs: serial keys %v, parallel keys %v", serialGrouped.Keys, parallelGrouped.Keys)
+	}
+	if len(serialGrouped.Keys) <= minParallelGroups {
+		t.Fatalf("expected more than %d groups, got %d", minParallelGroups, len(serialGrouped.Keys))
+	}
+	if len(serialTimelines) != len(parallelTimelines) {
+		t.Fatalf("expected %d timelines, got %d", len(serialTimelines), len(parallelTimelines))
+	}
+	for i := range serialTimelines {
+		if !reflect.DeepEqual(serialTimelines[i].Annotation, parallelTimelines[i].Annotation) ||
+			!reflect.DeepEqual(serialTimelines[i].ZeroEntry, parallelTimelines[i].ZeroEntry) ||
+			!reflect.DeepEqual(serialTimelines[i].Entries, parallelTimelines[i].Entries) {
+			t.Errorf("timeline %d (%v) differs", i, serialTimelines[i].Annotation)
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package dsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pivotal-golang/lager"
+)
+
+//ParseMatcher compiles a textual query into a Matcher.
+//
+//A query is a boolean combination (`and`, `or`, `not` and parentheses) of comparisons.  Each comparison has the form
+//
+//	GETTER OPERATOR LITERAL
+//
+//where GETTER follows the syntax accepted by ParseGetter and OPERATOR is one of:
+//
+//	=~ !~          regular expression match (the literal must be a string)
+//	== !=          equality (numeric if both sides are numeric, textual otherwise)
+//	< <= > >=      numeric comparison
+//
+//Literals are double-quoted strings, numbers or bare words (e.g. an unquoted guid).  Within a string only \" and \\ are escapes, so
+//regular expressions are written as usual: "rep\.auction".  Log levels (DEBUG, INFO, ERROR, FATAL) are treated as numbers
+//and `time` compares against unix timestamps or RFC3339 strings.  For example:
+//
+//	source =~ "rep" and data.task-guid =~ "^abc" and level >= ERROR and time > 1424820500
+//
+//The bare words `true` and `false` are also valid queries.  Syntax errors are returned as a *ParseError.
+func ParseMatcher(query string) (Matcher, error) {
+	p, err := newQueryParser(query)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.errorf(p.peek(), "unexpected %s", p.peek())
+	}
+	return matcher, nil
+}
+
+//ParseGetter compiles a textual description of a Getter.
+//
+//A Getter is a `|` separated list of fields.  Fields are tried in order and the first one that is present is returned.
+//Valid fields are source, message, session, job, index, vm, level, time, error, trace and data.PATH where PATH is a
+//key path into the Entry's Data (as accepted by DataGetter).  For example:
+//
+//	data.Container.Handle|data.guid
+func ParseGetter(description string) (Getter, error) {
+	p, err := newQueryParser(description)
+	if err != nil {
+		return nil, err
+	}
+	getter, err := p.parseGetter()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.errorf(p.peek(), "unexpected %s", p.peek())
+	}
+	return getter, nil
+}
+
+//ParseError is returned when a query fails to parse.  Position is the byte offset of the offending token.
+type ParseError struct {
+	Query    string
+	Position int
+	Message  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d:\n\t%s\n\t%s^", e.Message, e.Position, e.Query, strings.Repeat(" ", e.Position))
+}
+
+var queryFields = map[string]Getter{
+	"source":  GetSource,
+	"message": GetMessage,
+	"session": GetSession,
+	"job":     GetJob,
+	"index":   GetIndex,
+	"vm":      GetVM,
+	"level":   GetLogLevel,
+	"time":    GetTimestamp,
+	"error": GetterFunc(func(entry Entry) (interface{}, bool) {
+		if entry.Error == nil {
+			return nil, false
+		}
+		return entry.Error.Error(), true
+	}),
+	"trace": GetterFunc(func(entry Entry) (interface{}, bool) {
+		return entry.Trace, entry.Trace != ""
+	}),
+}
+
+var queryLogLevels = map[string]lager.LogLevel{
+	"DEBUG": lager.DEBUG,
+	"INFO":  lager.INFO,
+	"ERROR": lager.ERROR,
+	"FATAL": lager.FATAL,
+}
+
+// Lexer (private)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenPipe
+	tokenLeftParen
+	tokenRightParen
+)
+
+type queryToken struct {
+	kind     tokenKind
+	text     string
+	position int
+}
+
+func (t queryToken) String() string {
+	if t.kind == tokenEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+func lexQuery(query string) ([]queryToken, error) {
+	tokens := []queryToken{}
+	runes := []rune(query)
+	offsets := make([]int, len(runes)+1)
+	offset := 0
+	for i, r := range runes {
+		offsets[i] = offset
+		offset += len(string(r))
+	}
+	offsets[len(runes)] = offset
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, queryToken{tokenLeftParen, "(", offsets[i]})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{tokenRightParen, ")", offsets[i]})
+			i++
+		case r == '|':
+			tokens = append(tokens, queryToken{tokenPipe, "|", offsets[i]})
+			i++
+		case r == '"':
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, &ParseError{query, offsets[start], "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, queryToken{tokenString, unescapeQueryString(runes[start+1 : i-1]), offsets[start]})
+		case strings.ContainsRune("=!<>", r):
+			i++
+			if i < len(runes) && (runes[i] == '=' || runes[i] == '~') {
+				i++
+			}
+			operator := string(runes[start:i])
+			switch operator {
+			case "=~", "!~", "==", "!=", "<", "<=", ">", ">=":
+			default:
+				return nil, &ParseError{query, offsets[start], fmt.Sprintf("unknown operator %q", operator)}
+			}
+			tokens = append(tokens, queryToken{tokenOperator, operator, offsets[start]})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			if i < len(runes) && isWordRune(runes[i]) {
+				//not a number after all (e.g. a guid like 8f3c0a12-...)
+				for i < len(runes) && isWordRune(runes[i]) {
+					i++
+				}
+				tokens = append(tokens, queryToken{tokenWord, string(runes[start:i]), offsets[start]})
+				break
+			}
+			tokens = append(tokens, queryToken{tokenNumber, string(runes[start:i]), offsets[start]})
+		case isWordRune(r):
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{tokenWord, string(runes[start:i]), offsets[start]})
+		default:
+			return nil, &ParseError{query, offsets[i], fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	return append(tokens, queryToken{tokenEOF, "", offset}), nil
+}
+
+//unescapeQueryString decodes the contents of a string literal.  Only \" and \\ are escapes: every other backslash is kept
+//so that regular expressions can be written as they are everywhere else (e.g. "rep\.auction").
+func unescapeQueryString(runes []rune) string {
+	text := []rune{}
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+			i++
+		}
+		text = append(text, runes[i])
+	}
+	return string(text)
+}
+
+// Parser (private)
+
+type queryParser struct {
+	query  string
+	tokens []queryToken
+	index  int
+}
+
+func newQueryParser(query string) (*queryParser, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return &queryParser{query: query, tokens: tokens}, nil
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.index]
+}
+
+func (p *queryParser) next() queryToken {
+	token := p.tokens[p.index]
+	if token.kind != tokenEOF {
+		p.index++
+	}
+	return token
+}
+
+func (p *queryParser) isKeyword(keyword string) bool {
+	token := p.peek()
+	return token.kind == tokenWord && strings.EqualFold(token.text, keyword)
+}
+
+func (p *queryParser) errorf(token queryToken, format string, args ...interface{}) error {
+	return &ParseError{p.query, token.position, fmt.Sprintf(format, args...)}
+}
+
+func (p *queryParser) parseOr() (Matcher, error) {
+	matchers := []Matcher{}
+	for {
+		matcher, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+		if !p.isKeyword("or") {
+			break
+		}
+		p.next()
+	}
+	if len(matchers) == 1 {
+		return matchers[0], nil
+	}
+	return Or(matchers...), nil
+}
+
+func (p *queryParser) parseAnd() (Matcher, error) {
+	matchers := []Matcher{}
+	for {
+		matcher, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+		if !p.isKeyword("and") {
+			break
+		}
+		p.next()
+	}
+	if len(matchers) == 1 {
+		return matchers[0], nil
+	}
+	return And(matchers...), nil
+}
+
+func (p *queryParser) parseUnary() (Matcher, error) {
+	token := p.peek()
+	switch {
+	case p.isKeyword("not"):
+		p.next()
+		matcher, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(matcher), nil
+	case p.isKeyword("true"):
+		p.next()
+		return True(), nil
+	case p.isKeyword("false"):
+		p.next()
+		return False(), nil
+	case token.kind == tokenLeftParen:
+		p.next()
+		matcher, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRightParen {
+			return nil, p.errorf(p.peek(), "expected \")\" but found %s", p.peek())
+		}
+		p.next()
+		return matcher, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseGetter() (Getter, error) {
+	getters := []Getter{}
+	for {
+		token := p.next()
+		if token.kind != tokenWord {
+			return nil, p.errorf(token, "expected a field but found %s", token)
+		}
+		getter, ok := queryFields[strings.ToLower(token.text)]
+		if !ok {
+			if !strings.HasPrefix(token.text, "data.") || len(token.text) == len("data.") {
+				return nil, p.errorf(token, "unknown field %q", token.text)
+			}
+			getter = DataGetter(strings.TrimPrefix(token.text, "data."))
+		}
+		getters = append(getters, getter)
+		if p.peek().kind != tokenPipe {
+			break
+		}
+		p.next()
+	}
+	if len(getters) == 1 {
+		return getters[0], nil
+	}
+	return GetterFunc(func(entry Entry) (interface{}, bool) {
+		for _, getter := range getters {
+			if value, ok := getter.Get(entry); ok {
+				return value, true
+			}
+		}
+		return nil, false
+	}), nil
+}
+
+func (p *queryParser) parseComparison() (Matcher, error) {
+	getter, err := p.parseGetter()
+	if err != nil {
+		return nil, err
+	}
+
+	operator := p.next()
+	if operator.kind != tokenOperator {
+		return nil, p.errorf(operator, "expected an operator but found %s", operator)
+	}
+
+	literal := p.next()
+	if literal.kind != tokenString && literal.kind != tokenNumber && literal.kind != tokenWord {
+		return nil, p.errorf(literal, "expected a value but found %s", literal)
+	}
+
+	switch operator.text {
+	case "=~", "!~":
+		if literal.kind == tokenNumber {
+			return nil, p.errorf(literal, "expected a regular expression but found %s", literal)
+		}
+		re, err := regexp.Compile(literal.text)
+		if err != nil {
+			return nil, p.errorf(literal, "invalid regular expression: %s", err.Error())
+		}
+		negate := operator.text == "!~"
+		return MatcherFunc(func(entry Entry) bool {
+			value, ok := getter.Get(entry)
+			if !ok {
+				return false
+			}
+			return re.MatchString(queryString(value)) != negate
+		}), nil
+	case "==", "!=":
+		number, isNumber := queryLiteralNumber(literal)
+		negate := operator.text == "!="
+		return MatcherFunc(func(entry Entry) bool {
+			value, ok := getter.Get(entry)
+			if !ok {
+				return false
+			}
+			if isNumber {
+				if valueNumber, ok := queryNumber(value); ok {
+					return (valueNumber == number) != negate
+				}
+			}
+			return (queryString(value) == literal.text) != negate
+		}), nil
+	}
+
+	number, isNumber := queryLiteralNumber(literal)
+	if !isNumber {
+		return nil, p.errorf(literal, "expected a number, log level or timestamp but found %s", literal)
+	}
+	compare := map[string]func(a, b float64) bool{
+		"<":  func(a, b float64) bool { return a < b },
+		"<=": func(a, b float64) bool { return a <= b },
+		">":  func(a, b float64) bool { return a > b },
+		">=": func(a, b float64) bool { return a >= b },
+	}[operator.text]
+	return MatcherFunc(func(entry Entry) bool {
+		value, ok := getter.Get(entry)
+		if !ok {
+			return false
+		}
+		valueNumber, ok := queryNumber(value)
+		if !ok {
+			return false
+		}
+		return compare(valueNumber, number)
+	}), nil
+}
+
+// Value coercion (private)
+
+func queryLiteralNumber(literal queryToken) (float64, bool) {
+	if level, ok := queryLogLevels[strings.ToUpper(literal.text)]; ok && literal.kind == tokenWord {
+		return float64(level), true
+	}
+	if literal.kind == tokenString {
+		if t, err := time.Parse(time.RFC3339Nano, literal.text); err == nil {
+			return queryNumber(t)
+		}
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(literal.text, 64)
+	return f, err == nil
+}
+
+func queryNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case lager.LogLevel:
+		return float64(v), true
+	case time.Time:
+		return float64(v.UnixNano()) / 1e9, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func queryString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+	return fmt.Sprintf("%v", value)
+}
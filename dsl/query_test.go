@@ -0,0 +1,123 @@
+package dsl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+func newTestQueryEntry() Entry {
+	entry := newTestEntry("rep.auction.start", 1424820600, lager.Data{
+		"task-guid": "abc123",
+		"guid":      "8f3c0a12-abc",
+		"count":     3.0,
+		"container": map[string]interface{}{"handle": "xyz"},
+	})
+	entry.Source = "rep"
+	entry.LogLevel = lager.ERROR
+	entry.Job = "cell"
+	entry.Index = 2
+	return entry
+}
+
+func TestParseMatcher(t *testing.T) {
+	entry := newTestQueryEntry()
+
+	cases := map[string]bool{
+		`true`:                                        true,
+		`false`:                                       false,
+		`source =~ "rep"`:                             true,
+		`source !~ "rep"`:                             false,
+		`message =~ "^rep\.auction"`:                  true,
+		`message =~ "^rep\\.auction"`:                 true,
+		`message =~ "^rep\.auctioneer"`:               false,
+		`message =~ "rep\.\"auction"`:                 false,
+		`data.guid == 8f3c0a12-abc`:                   true,
+		`data.guid == "8f3c0a12-abc"`:                 true,
+		`data.guid =~ 8f3c`:                           true,
+		`data.count == 3.0`:                           true,
+		`data.task-guid == abc123`:                    true,
+		`data.task-guid != abc123`:                    false,
+		`data.container.handle == "xyz"`:              true,
+		`data.missing == "xyz"`:                       false,
+		`data.count == 3`:                             true,
+		`data.count > 2 and data.count <= 3`:          true,
+		`data.count < 3`:                              false,
+		`level >= ERROR`:                              true,
+		`level == INFO`:                               false,
+		`time > 1424820500`:                           true,
+		`time < "2015-02-24T23:30:00Z"`:               false,
+		`vm == "cell/2"`:                              true,
+		`job == cell and index == 2`:                  true,
+		`data.missing|data.task-guid =~ "abc"`:        true,
+		`source == bbs or message =~ "auction"`:       true,
+		`not (source == bbs or message =~ "auction")`: false,
+		`not source == bbs and level == ERROR`:        true,
+	}
+
+	for query, expected := range cases {
+		matcher, err := ParseMatcher(query)
+		if err != nil {
+			t.Errorf("%s: failed to parse: %s", query, err)
+			continue
+		}
+		if matcher.Match(entry) != expected {
+			t.Errorf("%s: expected %t", query, expected)
+		}
+	}
+}
+
+func TestParseMatcherErrors(t *testing.T) {
+	cases := map[string]int{
+		`source =~`:               9,
+		`source "rep"`:            7,
+		`bogus == 1`:              0,
+		`source =~ "rep" and`:     19,
+		`(source =~ "rep"`:        16,
+		`data.count > "abc"`:      13,
+		`source =~ "("`:           10,
+		`source =~ "rep" "extra"`: 16,
+	}
+
+	for query, position := range cases {
+		_, err := ParseMatcher(query)
+		parseErr, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("%s: expected a *ParseError, got %v", query, err)
+			continue
+		}
+		if parseErr.Position != position {
+			t.Errorf("%s: expected an error at %d, got %d (%s)", query, position, parseErr.Position, parseErr)
+		}
+	}
+}
+
+func TestParseGetter(t *testing.T) {
+	entry := newTestQueryEntry()
+
+	cases := map[string]interface{}{
+		`source`:                                "rep",
+		`vm`:                                    "cell/2",
+		`data.container.handle`:                 "xyz",
+		`data.missing|data.task-guid`:           "abc123",
+		`data.container.missing|data.task-guid`: "abc123",
+		`time`:                                  time.Unix(1424820600, 0),
+	}
+
+	for description, expected := range cases {
+		getter, err := ParseGetter(description)
+		if err != nil {
+			t.Errorf("%s: failed to parse: %s", description, err)
+			continue
+		}
+		value, ok := getter.Get(entry)
+		if !ok || value != expected {
+			t.Errorf("%s: expected %v, got %v", description, expected, value)
+		}
+	}
+
+	if _, err := ParseGetter(`source ==`); err == nil {
+		t.Errorf("expected a trailing operator to fail to parse")
+	}
+}
@@ -0,0 +1,216 @@
+package dsl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+//SessionNode is a node in a lager session tree.
+//
+//Lager sessions are hierarchical: session 4.12.3 is nested in session 4.12 which is nested in session 4.
+//Each SessionNode collects the Entries logged directly in its session (Entries), the Entries that failed (Errors: logged at ERROR or FATAL, or with an error),
+//and the nested sessions (Children, ordered by the time they first log).
+//
+//FirstEntry and LastEntry span the session *and* all its descendants -- Duration is the time between them.
+//
+//The root of a session tree has an empty Session and holds entries logged outside of any session.
+type SessionNode struct {
+	Source     string
+	VM         string
+	Session    string
+	Entries    Entries
+	Errors     Entries
+	FirstEntry Entry
+	LastEntry  Entry
+	Children   []*SessionNode
+	Parent     *SessionNode
+}
+
+//SessionTrees is a collection of session trees, one per VM
+type SessionTrees []*SessionNode
+
+//SessionTrees builds the session tree for the passed-in source.
+//
+//Session IDs are only unique within a single process so one tree is built per VM; the trees are sorted by VM.
+func (e Entries) SessionTrees(source string) SessionTrees {
+	sorted := make(Entries, len(e))
+	copy(sorted, e)
+	sort.Stable(sorted)
+
+	roots := map[string]*SessionNode{}
+	nodes := map[string]map[string]*SessionNode{}
+	for _, entry := range sorted {
+		if entry.Source != source {
+			continue
+		}
+
+		vm := entry.VM()
+		root, ok := roots[vm]
+		if !ok {
+			root = &SessionNode{Source: source, VM: vm}
+			roots[vm] = root
+			nodes[vm] = map[string]*SessionNode{}
+		}
+
+		node := root
+		if entry.Session != "" {
+			node = sessionNode(root, nodes[vm], entry.Session)
+		}
+
+		node.Entries = append(node.Entries, entry)
+		if entry.LogLevel >= lager.ERROR || entry.Error != nil {
+			node.Errors = append(node.Errors, entry)
+		}
+		for n := node; n != nil; n = n.Parent {
+			if n.FirstEntry.IsZero() {
+				n.FirstEntry = entry
+			}
+			n.LastEntry = entry
+		}
+	}
+
+	trees := SessionTrees{}
+	for _, root := range roots {
+		trees = append(trees, root)
+	}
+	sort.Sort(trees)
+
+	return trees
+}
+
+func sessionNode(root *SessionNode, nodes map[string]*SessionNode, session string) *SessionNode {
+	node, ok := nodes[session]
+	if ok {
+		return node
+	}
+
+	parent := root
+	if i := strings.LastIndex(session, "."); i >= 0 {
+		parent = sessionNode(root, nodes, session[:i])
+	}
+
+	node = &SessionNode{
+		Source:  root.Source,
+		VM:      root.VM,
+		Session: session,
+		Parent:  parent,
+	}
+	parent.Children = append(parent.Children, node)
+	nodes[session] = node
+
+	return node
+}
+
+//Find returns the nodes (one per VM at most) for the passed-in session
+func (s SessionTrees) Find(session string) []*SessionNode {
+	found := []*SessionNode{}
+	for _, root := range s {
+		if node, ok := root.Find(session); ok {
+			found = append(found, node)
+		}
+	}
+	return found
+}
+
+func (s SessionTrees) Len() int           { return len(s) }
+func (s SessionTrees) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s SessionTrees) Less(i, j int) bool { return s[i].VM < s[j].VM }
+
+//Find returns the node for the passed-in session if it is this node or one of its descendants
+func (n *SessionNode) Find(session string) (*SessionNode, bool) {
+	if n.Session == session {
+		return n, true
+	}
+	for _, child := range n.Children {
+		if child.Session == session || strings.HasPrefix(session, child.Session+".") {
+			return child.Find(session)
+		}
+	}
+	return nil, false
+}
+
+//Name returns a human readable name for the session.
+//
+//Lager messages look like SOURCE.SESSION-NAME.ACTION (e.g. rep.auction-perform-work.starting), the name is the message of the session's first entry without its ACTION.
+//The name of a root node is its source.
+func (n *SessionNode) Name() string {
+	if n.Session == "" {
+		return n.Source
+	}
+	if len(n.Entries) == 0 {
+		return n.Session
+	}
+	message := n.Entries[0].Message
+	if i := strings.LastIndex(message, "."); i >= 0 {
+		return message[:i]
+	}
+	return message
+}
+
+//Duration returns the time spanned by the session and its descendants
+func (n *SessionNode) Duration() time.Duration {
+	return n.LastEntry.Timestamp.Sub(n.FirstEntry.Timestamp)
+}
+
+//Depth returns the number of ancestors of the node
+func (n *SessionNode) Depth() int {
+	depth := 0
+	for p := n.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return depth
+}
+
+//Walk is an iterator that visits the node and all of its descendants, depth first.
+//depth is relative to the node Walk is called on.  Returning non-nil will cause the iterator to abort.
+func (n *SessionNode) Walk(f func(node *SessionNode, depth int) error) error {
+	return n.walk(f, 0)
+}
+
+func (n *SessionNode) walk(f func(node *SessionNode, depth int) error, depth int) error {
+	err := f(n, depth)
+	if err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		err := child.walk(f, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//MaxDepth returns the depth of the deepest descendant (relative to the node)
+func (n *SessionNode) MaxDepth() int {
+	maxDepth := 0
+	n.Walk(func(node *SessionNode, depth int) error {
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		return nil
+	})
+	return maxDepth
+}
+
+//String() produces an indented representation of the tree: session, name, duration, number of entries and errors
+func (n *SessionNode) String() string {
+	s := []string{}
+	n.Walk(func(node *SessionNode, depth int) error {
+		errors := ""
+		if len(node.Errors) > 0 {
+			errors = fmt.Sprintf(" %d errors", len(node.Errors))
+		}
+		session := node.Session
+		if session == "" {
+			session = node.VM
+		}
+		s = append(s, fmt.Sprintf("%s[%s] %s: %s (%d entries%s)", strings.Repeat("  ", depth), session, node.Name(), node.Duration(), len(node.Entries), errors))
+		return nil
+	})
+	return strings.Join(s, "\n")
+}
@@ -0,0 +1,172 @@
+package dsl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//TimeSeries is a sequence of values sampled in consecutive, equally sized time buckets
+//
+//Start is the beginning of the first bucket, relative to the ZeroEntry of the Timelines the series was computed from.
+type TimeSeries struct {
+	Name   string
+	Start  time.Duration
+	Step   time.Duration
+	Values []float64
+}
+
+//Times returns the time at the beginning of each bucket
+func (t TimeSeries) Times() []time.Duration {
+	times := make([]time.Duration, len(t.Values))
+	for i := range t.Values {
+		times[i] = t.Start + t.Step*time.Duration(i)
+	}
+	return times
+}
+
+//Max returns the largest value in the series
+func (t TimeSeries) Max() float64 {
+	max := 0.0
+	for _, value := range t.Values {
+		if value > max {
+			max = value
+		}
+	}
+	return max
+}
+
+//TimeSeriesSlice is a collection of TimeSeries sharing the same buckets
+type TimeSeriesSlice []TimeSeries
+
+//Sum returns a TimeSeries whose values are the sum of the values of all the series in the slice
+func (t TimeSeriesSlice) Sum() TimeSeries {
+	if len(t) == 0 {
+		return TimeSeries{}
+	}
+	sum := TimeSeries{
+		Name:   "total",
+		Start:  t[0].Start,
+		Step:   t[0].Step,
+		Values: make([]float64, len(t[0].Values)),
+	}
+	for _, series := range t {
+		for i, value := range series.Values {
+			sum.Values[i] += value
+		}
+	}
+	return sum
+}
+
+//String renders the slice as a table with one row per bucket and one column per series
+func (t TimeSeriesSlice) String() string {
+	if len(t) == 0 {
+		return ""
+	}
+	header := []string{"time"}
+	for _, series := range t {
+		header = append(header, series.Name)
+	}
+	rows := []string{strings.Join(header, "\t")}
+	for i, dt := range t[0].Times() {
+		row := []string{fmt.Sprintf("%.3f", dt.Seconds())}
+		for _, series := range t {
+			row = append(row, fmt.Sprintf("%.2f", series.Values[i]))
+		}
+		rows = append(rows, strings.Join(row, "\t"))
+	}
+	return strings.Join(rows, "\n")
+}
+
+//InFlight computes, for each TimelinePoint, how many timelines are in flight between the previous TimelinePoint and this one over time.
+//
+//e.g. for a TimelinePoint named Allocating-Container the resulting TimeSeries tells you how many timelines had reached the
+//previous TimelinePoint but had not yet reached Allocating-Container in each bucket.
+//
+//The buckets are step wide and span StartsAfter() through EndsAfter().  Values are time-weighted averages: a timeline that is
+//in flight for half of a bucket contributes 0.5.  The first TimelinePoint has no preceding point and is always zero.
+func (t Timelines) InFlight(step time.Duration) TimeSeriesSlice {
+	start, n := t.timeSeriesBuckets(step)
+
+	slice := TimeSeriesSlice{}
+	for i, timelinePoint := range t.Description() {
+		series := TimeSeries{
+			Name:   timelinePoint.Name,
+			Start:  start,
+			Step:   step,
+			Values: make([]float64, n),
+		}
+		for _, timeline := range t {
+			pair, ok := timeline.EntryPair(i)
+			if i == 0 || !ok {
+				continue
+			}
+			from := pair.FirstEntry.Timestamp.Sub(timeline.ZeroEntry.Timestamp) - start
+			to := pair.SecondEntry.Timestamp.Sub(timeline.ZeroEntry.Timestamp) - start
+			if from < 0 || to <= from {
+				continue
+			}
+			for bucket := int(from / step); bucket < n && time.Duration(bucket)*step < to; bucket++ {
+				low := maxDuration(from, time.Duration(bucket)*step)
+				high := minDuration(to, time.Duration(bucket+1)*step)
+				series.Values[bucket] += float64(high-low) / float64(step)
+			}
+		}
+		slice = append(slice, series)
+	}
+
+	return slice
+}
+
+//Throughput computes, for each TimelinePoint, the number of timelines that reached the TimelinePoint in each bucket.
+//
+//The buckets are step wide and span StartsAfter() through EndsAfter().  Divide by step.Seconds() for a rate.
+func (t Timelines) Throughput(step time.Duration) TimeSeriesSlice {
+	start, n := t.timeSeriesBuckets(step)
+
+	slice := TimeSeriesSlice{}
+	for i, timelinePoint := range t.Description() {
+		series := TimeSeries{
+			Name:   timelinePoint.Name,
+			Start:  start,
+			Step:   step,
+			Values: make([]float64, n),
+		}
+		for _, timeline := range t {
+			entry := timeline.Entries[i]
+			if entry.IsZero() {
+				continue
+			}
+			bucket := int((entry.Timestamp.Sub(timeline.ZeroEntry.Timestamp) - start) / step)
+			if bucket >= 0 && bucket < n {
+				series.Values[bucket] += 1
+			}
+		}
+		slice = append(slice, series)
+	}
+
+	return slice
+}
+
+func (t Timelines) timeSeriesBuckets(step time.Duration) (time.Duration, int) {
+	if step <= 0 {
+		panic("time series step must be positive")
+	}
+	start := t.StartsAfter()
+	end := t.EndsAfter()
+	return start, int((end-start)/step) + 1
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -21,14 +21,16 @@ type Timeline struct {
 
 //String() produces a textual representation of the timeline.
 //The TimelinePoint name and elapsed time are printed for each TimelinePoint in the Timeline's TimelineDescription.
-//If a TimelinePoint is missing a corresponding Entry the TimelinePoint's name is rendered in red.
+//If a TimelinePoint is missing a corresponding Entry the TimelinePoint's name is rendered in red (gray if the TimelinePoint is Optional).
 func (t Timeline) String() string {
 	s := []string{fmt.Sprintf("%s:", say.Green("%s", t.Annotation))}
 
 	runningTimestamp := t.ZeroEntry.Timestamp
 	for i := 0; i < len(t.Description); i++ {
-		if t.Entries[i].IsZero() {
-			s = append(s, say.Red(t.Description[i].Name))
+		if t.Entries[i].IsZero() && t.Description[i].Optional {
+			s = append(s, say.Gray("%s", t.Description[i].Name))
+		} else if t.Entries[i].IsZero() {
+			s = append(s, say.Red("%s", t.Description[i].Name))
 		} else {
 			s = append(s, fmt.Sprintf("%s:%s", t.Description[i].Name, t.Entries[i].Timestamp.Sub(runningTimestamp)))
 			runningTimestamp = t.Entries[i].Timestamp
@@ -63,6 +65,9 @@ func (t Timeline) String() string {
 //	timeline.EntryPair(0)
 //
 //returns the ZeroEntry as the FirstEntry in the pair.
+//
+//Missing Optional TimelinePoints are skipped over: the FirstEntry is then the Entry of the closest preceding TimelinePoint that
+//is present (or the ZeroEntry if all preceding TimelinePoints are missing and Optional).
 func (t Timeline) EntryPair(index int) (EntryPair, bool) {
 	if index >= len(t.Description) {
 		return EntryPair{}, false
@@ -70,18 +75,22 @@ func (t Timeline) EntryPair(index int) (EntryPair, bool) {
 	if t.Entries[index].IsZero() {
 		return EntryPair{}, false
 	}
-	if index == 0 {
+	previous := index - 1
+	for previous >= 0 && t.Entries[previous].IsZero() && t.Description[previous].Optional {
+		previous--
+	}
+	if previous < 0 {
 		return EntryPair{
 			FirstEntry:  t.ZeroEntry,
-			SecondEntry: t.Entries[0],
+			SecondEntry: t.Entries[index],
 			Annotation:  t.Annotation,
 		}, true
 	}
-	if t.Entries[index-1].IsZero() {
+	if t.Entries[previous].IsZero() {
 		return EntryPair{}, false
 	}
 	return EntryPair{
-		FirstEntry:  t.Entries[index-1],
+		FirstEntry:  t.Entries[previous],
 		SecondEntry: t.Entries[index],
 		Annotation:  t.Annotation,
 	}, true
@@ -92,10 +101,10 @@ func (t Timeline) First(matcher Matcher) (Entry, bool) {
 	return t.Entries.First(matcher)
 }
 
-//IsComplete returns true if all events in the timeline are present
+//IsComplete returns true if all events in the timeline are present.  Optional events may be missing.
 func (t Timeline) IsComplete() bool {
 	for i := range t.Description {
-		if t.Entries[i].IsZero() {
+		if t.Entries[i].IsZero() && !t.Description[i].Optional {
 			return false
 		}
 	}
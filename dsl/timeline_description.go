@@ -1,13 +1,66 @@
 package dsl
 
+import "fmt"
+
 //A TimelinePoint describes a point in a TimelineDescription
 //
 //The Name is any string to associate with the TimelinePoint
 //The Matcher is used to identify Entries that should be associated with the TimelinePoint
+//The Policy picks which of the matching Entries is associated with the TimelinePoint (see MatchPolicy).  N is used by NthMatch.
+//An Optional TimelinePoint that has no matching Entry does not make the Timeline incomplete.
 type TimelinePoint struct {
-	Name    string
-	Matcher Matcher
-	Squash  float64
+	Name     string
+	Matcher  Matcher
+	Squash   float64
+	Policy   MatchPolicy
+	N        int
+	Optional bool
+}
+
+//MatchPolicy determines which of the Entries matching a TimelinePoint's Matcher is associated with the TimelinePoint
+type MatchPolicy int
+
+const (
+	//FirstMatch picks the first matching Entry.  This is the default.
+	FirstMatch MatchPolicy = iota
+	//LastMatch picks the last matching Entry (e.g. the allocation that finally succeeded after a failed auction)
+	LastMatch
+	//NthMatch picks the TimelinePoint's Nth (1-based) matching Entry
+	NthMatch
+	//FirstMatchAfterPrevious picks the first matching Entry that occurs no earlier than the Entry picked for the closest
+	//preceding TimelinePoint.  This keeps retries from producing timelines that go back in time.
+	FirstMatchAfterPrevious
+)
+
+var matchPolicyNames = map[MatchPolicy]string{
+	FirstMatch:              "first",
+	LastMatch:               "last",
+	NthMatch:                "nth",
+	FirstMatchAfterPrevious: "after-previous",
+}
+
+func (p MatchPolicy) String() string {
+	return matchPolicyNames[p]
+}
+
+//ParseMatchPolicy parses the name of a MatchPolicy: one of first, last, nth or after-previous.  The empty string is FirstMatch.
+func ParseMatchPolicy(name string) (MatchPolicy, error) {
+	if name == "" {
+		return FirstMatch, nil
+	}
+	for policy, policyName := range matchPolicyNames {
+		if name == policyName {
+			return policy, nil
+		}
+	}
+	return FirstMatch, fmt.Errorf("unknown match policy: %s", name)
+}
+
+func (p TimelinePoint) nth() int {
+	if p.N < 1 {
+		return 1
+	}
+	return p.N
 }
 
 //A TimelineDescription is an ordered list of TimelinePoints.
@@ -0,0 +1,104 @@
+package dsl
+
+import "testing"
+
+func TestMatchPolicies(t *testing.T) {
+	entries := Entries{
+		newTestEntry("a", 1, nil),
+		newTestEntry("b", 2, nil),
+		newTestEntry("a", 3, nil),
+		newTestEntry("b", 4, nil),
+		newTestEntry("b", 5, nil),
+	}
+
+	cases := []struct {
+		name        string
+		description TimelineDescription
+		expected    []float64
+	}{
+		{"first", TimelineDescription{
+			{Name: "a", Matcher: MatchMessage("a"), Squash: 1},
+			{Name: "b", Matcher: MatchMessage("b"), Squash: 1},
+		}, []float64{1, 2}},
+		{"last", TimelineDescription{
+			{Name: "a", Matcher: MatchMessage("a"), Squash: 1, Policy: LastMatch},
+			{Name: "b", Matcher: MatchMessage("b"), Squash: 1, Policy: LastMatch},
+		}, []float64{3, 5}},
+		{"nth", TimelineDescription{
+			{Name: "a", Matcher: MatchMessage("a"), Squash: 1, Policy: NthMatch, N: 2},
+			{Name: "b", Matcher: MatchMessage("b"), Squash: 1, Policy: NthMatch, N: 3},
+		}, []float64{3, 5}},
+		{"after-previous", TimelineDescription{
+			{Name: "a", Matcher: MatchMessage("a"), Squash: 1, Policy: LastMatch},
+			{Name: "b", Matcher: MatchMessage("b"), Squash: 1, Policy: FirstMatchAfterPrevious},
+		}, []float64{3, 4}},
+		{"nth beyond the last match", TimelineDescription{
+			{Name: "a", Matcher: MatchMessage("a"), Squash: 1},
+			{Name: "b", Matcher: MatchMessage("b"), Squash: 1, Policy: NthMatch, N: 4},
+		}, []float64{1, 0}},
+	}
+
+	for _, c := range cases {
+		timeline := entries.ConstructTimeline(c.description, entries[0])
+		for i, expected := range c.expected {
+			entry := timeline.Entries[i]
+			if expected == 0 {
+				if !entry.IsZero() {
+					t.Errorf("%s: expected %s to be missing, got the entry at %s", c.name, c.description[i].Name, entry.Timestamp)
+				}
+				continue
+			}
+			if entry.IsZero() || entry.Timestamp != newTestEntry("", expected, nil).Timestamp {
+				t.Errorf("%s: expected %s to be the entry at %gs, got %s", c.name, c.description[i].Name, expected, entry.Timestamp)
+			}
+		}
+	}
+}
+
+func TestOptionalTimelinePoints(t *testing.T) {
+	entries := Entries{
+		newTestEntry("a", 1, nil),
+		newTestEntry("b", 2, nil),
+	}
+
+	description := TimelineDescription{
+		{Name: "a", Matcher: MatchMessage("a")},
+		{Name: "c", Matcher: MatchMessage("c"), Optional: true},
+		{Name: "b", Matcher: MatchMessage("b")},
+	}
+	timeline := entries.ConstructTimeline(description, entries[0])
+	if !timeline.IsComplete() {
+		t.Errorf("expected a timeline missing only an optional point to be complete")
+	}
+	if !timeline.Entries[1].IsZero() {
+		t.Errorf("expected the optional point to be missing")
+	}
+
+	description[1].Optional = false
+	timeline = entries.ConstructTimeline(description, entries[0])
+	if timeline.IsComplete() {
+		t.Errorf("expected a timeline missing a required point to be incomplete")
+	}
+
+	description[1].Optional = true
+	timeline = entries[:1].ConstructTimeline(description, entries[0])
+	failures := NewGroupedEntries().Failures(Timelines{timeline})
+	if len(failures) != 1 || failures[0].Stage() != "b" {
+		t.Errorf("expected the timeline to fail at b, not at the optional c")
+	}
+}
+
+func TestParseMatchPolicy(t *testing.T) {
+	for _, policy := range []MatchPolicy{FirstMatch, LastMatch, NthMatch, FirstMatchAfterPrevious} {
+		parsed, err := ParseMatchPolicy(policy.String())
+		if err != nil || parsed != policy {
+			t.Errorf("expected %s to parse, got %s (%v)", policy, parsed, err)
+		}
+	}
+	if policy, err := ParseMatchPolicy(""); err != nil || policy != FirstMatch {
+		t.Errorf("expected the empty policy to be first, got %s (%v)", policy, err)
+	}
+	if _, err := ParseMatchPolicy("second"); err == nil {
+		t.Errorf("expected an unknown policy to fail to parse")
+	}
+}
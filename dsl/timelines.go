@@ -188,6 +188,8 @@ func (t Timelines) ToCSV(w io.Writer) {
 		}
 		csvWriter.Write(row)
 	}
+
+	csvWriter.Flush()
 }
 
 // Sorters (private)
@@ -0,0 +1,164 @@
+package dsl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//Window is a wall-clock time window.  It includes Start and excludes End.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+//Contains returns true if t lies within the window
+func (w Window) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+//Duration returns the width of the window
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+//Matcher returns a Matcher that matches entries whose timestamp lies within the window
+//
+//Unlike MatchBetween the window includes its Start, so consecutive windows never drop an entry.
+func (w Window) Matcher() Matcher {
+	return MatcherFunc(func(entry Entry) bool {
+		return w.Contains(entry.Timestamp)
+	})
+}
+
+func (w Window) String() string {
+	return fmt.Sprintf("%s - %s", w.Start.Format("15:04:05.000"), w.End.Format("15:04:05.000"))
+}
+
+//Windows is a slice of Window
+type Windows []Window
+
+//FixedWindows splits [start, end) into consecutive, non-overlapping windows of the passed-in width.
+//The last window is extended to width (and may therefore end after end).
+func FixedWindows(start time.Time, end time.Time, width time.Duration) Windows {
+	return SlidingWindows(start, end, width, width)
+}
+
+//SlidingWindows returns windows of the passed-in width, starting at start and every step thereafter until end.
+//
+//Windows overlap when step is smaller than width: this smooths out noise at the cost of counting each entry in several windows.
+func SlidingWindows(start time.Time, end time.Time, width time.Duration, step time.Duration) Windows {
+	if width <= 0 || step <= 0 {
+		panic("window width and step must be positive")
+	}
+	windows := Windows{}
+	for t := start; t.Before(end); t = t.Add(step) {
+		windows = append(windows, Window{t, t.Add(width)})
+	}
+	return windows
+}
+
+//Span returns the smallest window that contains the FirstEntry and SecondEntry of every pair
+func (e EntryPairs) Span() Window {
+	span := Window{}
+	for i, pair := range e {
+		if i == 0 || pair.FirstEntry.Timestamp.Before(span.Start) {
+			span.Start = pair.FirstEntry.Timestamp
+		}
+		if i == 0 || !pair.SecondEntry.Timestamp.Before(span.End) {
+			span.End = pair.SecondEntry.Timestamp.Add(time.Nanosecond)
+		}
+	}
+	return span
+}
+
+//InWindow returns the EntryPairs that end (i.e. whose SecondEntry occurs) within the window
+func (e EntryPairs) InWindow(window Window) EntryPairs {
+	pairs := EntryPairs{}
+	for _, pair := range e {
+		if window.Contains(pair.SecondEntry.Timestamp) {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+//DTStatsByWindow computes the DTStats of the pairs that end within each window
+func (e EntryPairs) DTStatsByWindow(windows Windows) []DTStats {
+	stats := []DTStats{}
+	for _, window := range windows {
+		stats = append(stats, e.InWindow(window).DTStats())
+	}
+	return stats
+}
+
+//Span returns the smallest wall-clock window that contains every (non-zero) entry in the Timelines
+func (t Timelines) Span() Window {
+	span := Window{}
+	for _, timeline := range t {
+		for _, entry := range timeline.Entries {
+			if entry.IsZero() {
+				continue
+			}
+			if span.Start.IsZero() || entry.Timestamp.Before(span.Start) {
+				span.Start = entry.Timestamp
+			}
+			if !entry.Timestamp.Before(span.End) {
+				span.End = entry.Timestamp.Add(time.Nanosecond)
+			}
+		}
+	}
+	return span
+}
+
+//WindowedDTStats holds the DTStats of each TimelinePoint for the EntryPairs that end within a Window
+type WindowedDTStats struct {
+	Window       Window
+	DTStatsSlice DTStatsSlice
+}
+
+//WindowedDTStatsSlice is a slice of WindowedDTStats, one per window
+type WindowedDTStatsSlice []WindowedDTStats
+
+//DTStatsByWindow computes, for each window, the DTStats of each TimelinePoint (see Timelines.DTStatsSlice)
+//using only the EntryPairs that end within the window.
+//
+//Use FixedWindows or SlidingWindows over t.Span() to, e.g., see how latencies evolve over a multi-hour run:
+//
+//	span := timelines.Span()
+//	timelines.DTStatsByWindow(SlidingWindows(span.Start, span.End, 5*time.Minute, time.Minute))
+func (t Timelines) DTStatsByWindow(windows Windows) WindowedDTStatsSlice {
+	windowed := WindowedDTStatsSlice{}
+	for _, window := range windows {
+		windowed = append(windowed, WindowedDTStats{Window: window, DTStatsSlice: DTStatsSlice{}})
+	}
+
+	for i, timelinePoint := range t.Description() {
+		for j, stats := range t.EntryPairs(i).DTStatsByWindow(windows) {
+			stats.Name = timelinePoint.Name
+			windowed[j].DTStatsSlice = append(windowed[j].DTStatsSlice, stats)
+		}
+	}
+
+	return windowed
+}
+
+//String renders one line per window with the number of pairs, the median and the P99 of each TimelinePoint
+func (w WindowedDTStatsSlice) String() string {
+	if len(w) == 0 {
+		return ""
+	}
+	header := []string{"window"}
+	for _, stats := range w[0].DTStatsSlice {
+		header = append(header, stats.Name+" (n, median, p99)")
+	}
+	rows := []string{strings.Join(header, "\t")}
+	for _, windowed := range w {
+		row := []string{windowed.Window.String()}
+		for _, stats := range windowed.DTStatsSlice {
+			row = append(row, fmt.Sprintf("%d, %s, %s", stats.N, stats.Median, stats.P99))
+		}
+		rows = append(rows, strings.Join(row, "\t"))
+	}
+	return strings.Join(rows, "\n")
+}
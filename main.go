@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/cloudfoundry-incubator/cicerone/commands"
+	"github.com/cloudfoundry-incubator/cicerone/converters"
 	"github.com/onsi/say"
 )
 
@@ -18,6 +19,8 @@ type Command interface {
 }
 
 var outputDir string
+var filter string
+var format string
 var comms []Command
 
 func init() {
@@ -30,13 +33,16 @@ func init() {
 		&commands.FezzikLRPs{},
 		&commands.AnalyzeCreateContainer{},
 		&commands.AnalyzeCellPerformance{},
-
-		//one-offs
-		// &commands.SlurpDisappearingCells{},
-		// &commands.AnalyzeDisappearingCells{},
+		&commands.Analyze{},
+		&commands.Compare{},
+		&commands.SessionTree{},
+		&commands.ListOperations{},
+		&commands.EventWindows{},
 	}
 
 	flag.StringVar(&outputDir, "output-dir", ".", "Output Directory to store plots")
+	flag.StringVar(&filter, "filter", "", `Only analyze entries matching this query (e.g. 'source =~ "rep" and level >= ERROR')`)
+	flag.StringVar(&format, "format", "", "Format of the log files to analyze (one of "+strings.Join(converters.FormatNames(), ", ")+").  Detected when omitted.")
 	flag.Parse()
 }
 
@@ -47,6 +53,24 @@ func main() {
 
 	args := flag.Args()
 
+	if filter != "" {
+		err := commands.SetFilter(filter)
+		if err != nil {
+			fmt.Println("Invalid --filter")
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if format != "" {
+		err := commands.SetFormat(format)
+		if err != nil {
+			fmt.Println("Invalid --format")
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}
+
 	for _, command := range comms {
 		commandName := strings.Split(command.Usage(), " ")[0]
 		if commandName == args[0] {
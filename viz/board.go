@@ -108,16 +108,42 @@ func (b *Board) AddSubPlot(plot *plot.Plot, rect Rect) {
 //Save saves the board (i.e. all subplots, appropriately laid out) to the specified filename.
 //It basically rips off the implementation of Save in plotinum to support various file formats.
 func (b *Board) Save(width, height float64, file string) (err error) {
+	c, err := b.render(width, height, strings.ToLower(filepath.Ext(file)), file)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err = c.WriteTo(f); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+//WriteSVG renders the board as SVG to the passed-in writer
+func (b *Board) WriteSVG(width, height float64, w io.Writer) error {
+	c, err := b.render(width, height, ".svg", "")
+	if err != nil {
+		return err
+	}
+	_, err = c.WriteTo(w)
+	return err
+}
+
+func (b *Board) render(width, height float64, ext string, title string) (io.WriterTo, error) {
 	w, h := vg.Inch*vg.Length(width), vg.Inch*vg.Length(height)
 	var c interface {
 		vg.Canvas
 		Size() (w, h vg.Length)
 		io.WriterTo
 	}
-	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	switch ext {
 
 	case ".eps":
-		c = vgeps.NewTitle(w, h, file)
+		c = vgeps.NewTitle(w, h, title)
 
 	case ".jpg", ".jpeg":
 		c = vgimg.JpegCanvas{Canvas: vgimg.New(w, h)}
@@ -135,7 +161,7 @@ func (b *Board) Save(width, height float64, file string) (err error) {
 		c = vgimg.TiffCanvas{Canvas: vgimg.New(w, h)}
 
 	default:
-		return fmt.Errorf("Unsupported file extension: %s", ext)
+		return nil, fmt.Errorf("Unsupported file extension: %s", ext)
 	}
 
 	for _, subplot := range b.SubPlots {
@@ -148,12 +174,5 @@ func (b *Board) Save(width, height float64, file string) (err error) {
 		subplot.Plot.Draw(drawArea)
 	}
 
-	f, err := os.Create(file)
-	if err != nil {
-		return err
-	}
-	if _, err = c.WriteTo(f); err != nil {
-		return err
-	}
-	return f.Close()
+	return c, nil
 }
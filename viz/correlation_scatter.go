@@ -3,6 +3,7 @@ package viz
 import (
 	"fmt"
 	"image/color"
+	"sort"
 
 	. "github.com/cloudfoundry-incubator/cicerone/dsl"
 	"github.com/gonum/plot"
@@ -28,7 +29,32 @@ func newCorrelationScatter(xDurations Durations, yDurations Durations, c color.C
 }
 
 //Constructs and returns a correlation board between all possible entry pairs
+//The first 20% of the timelines are highlighted in blue, the last 20% in red.
 func NewCorrelationBoard(timelines Timelines) (*UniformBoard, error) {
+	return newCorrelationBoard(timelines, false, func(xDurations Durations) (int, int) {
+		twentyPercent := int(float64(len(xDurations)) * 0.2)
+		return twentyPercent, len(xDurations) - twentyPercent
+	})
+}
+
+//NewQuantileCorrelationBoard is like NewCorrelationBoard but highlights points by the duration along the x axis:
+//points at or below the lower percentile are drawn in blue, points at or above the upper percentile in red.
+func NewQuantileCorrelationBoard(timelines Timelines, lower float64, upper float64) (*UniformBoard, error) {
+	return newCorrelationBoard(timelines, true, func(sortedXDurations Durations) (int, int) {
+		low := sortedXDurations.Percentile(lower)
+		high := sortedXDurations.Percentile(upper)
+		lowBoundary := sort.Search(len(sortedXDurations), func(k int) bool { return sortedXDurations[k] > low })
+		highBoundary := sort.Search(len(sortedXDurations), func(k int) bool { return sortedXDurations[k] >= high })
+		if highBoundary < lowBoundary {
+			highBoundary = lowBoundary
+		}
+		return lowBoundary, highBoundary
+	})
+}
+
+//newCorrelationBoard splits each scatter plot into three colored bands.
+//split returns the boundaries between the bands - if sortByX is true the points are ordered by x duration before splitting.
+func newCorrelationBoard(timelines Timelines, sortByX bool, split func(Durations) (int, int)) (*UniformBoard, error) {
 	//timelines must be complete!
 	timelines = timelines.CompleteTimelines()
 
@@ -40,26 +66,28 @@ func NewCorrelationBoard(timelines Timelines) (*UniformBoard, error) {
 			p, _ := plot.New()
 
 			iPairs, jPairs := timelines.MatchedEntryPairs(i, j)
+			if sortByX {
+				sort.Sort(matchedPairs{iPairs, jPairs})
+			}
 
 			xDurations := iPairs.Durations()
 			yDurations := jPairs.Durations()
 
-			twentyPercent := int(float64(len(xDurations)) * 0.2)
-			eightyPercent := len(xDurations) - twentyPercent
+			lowBoundary, highBoundary := split(xDurations)
 
-			s, err := newCorrelationScatter(xDurations[:twentyPercent], yDurations[:twentyPercent], color.RGBA{0, 0, 255, 255})
+			s, err := newCorrelationScatter(xDurations[:lowBoundary], yDurations[:lowBoundary], color.RGBA{0, 0, 255, 255})
 			if err != nil {
 				return nil, err
 			}
 			p.Add(s)
 
-			s, err = newCorrelationScatter(xDurations[twentyPercent:eightyPercent], yDurations[twentyPercent:eightyPercent], color.RGBA{0, 0, 0, 255})
+			s, err = newCorrelationScatter(xDurations[lowBoundary:highBoundary], yDurations[lowBoundary:highBoundary], color.RGBA{0, 0, 0, 255})
 			if err != nil {
 				return nil, err
 			}
 			p.Add(s)
 
-			s, err = newCorrelationScatter(xDurations[eightyPercent:], yDurations[eightyPercent:], color.RGBA{255, 0, 0, 255})
+			s, err = newCorrelationScatter(xDurations[highBoundary:], yDurations[highBoundary:], color.RGBA{255, 0, 0, 255})
 			if err != nil {
 				return nil, err
 			}
@@ -75,6 +103,19 @@ func NewCorrelationBoard(timelines Timelines) (*UniformBoard, error) {
 	return board, nil
 }
 
+//matchedPairs sorts two parallel EntryPairs by the durations in the first
+type matchedPairs struct {
+	x EntryPairs
+	y EntryPairs
+}
+
+func (m matchedPairs) Len() int           { return len(m.x) }
+func (m matchedPairs) Less(i, j int) bool { return m.x[i].DT() < m.x[j].DT() }
+func (m matchedPairs) Swap(i, j int) {
+	m.x[i], m.x[j] = m.x[j], m.x[i]
+	m.y[i], m.y[j] = m.y[j], m.y[i]
+}
+
 //Constructs and returns a correlation board between all possible entry pairs
 func NewGroupedCorrelationBoard(group *GroupedTimelines) (*UniformBoard, error) {
 	size := len(group.Description())
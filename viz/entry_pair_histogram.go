@@ -49,7 +49,24 @@ func NewScaledEntryPairsHistogram(pairs EntryPairs, n int, min time.Duration, ma
 	}
 }
 
+//NewEntryPairsHistogramBoard plots a histogram of the EntryPairs associated with each TimelinePoint.
+//The first 20% of the timelines are highlighted in blue, the last 20% in red.
 func NewEntryPairsHistogramBoard(timelines Timelines) *UniformBoard {
+	return newEntryPairsHistogramBoard(timelines, func(entryPairs EntryPairs) (EntryPairs, EntryPairs) {
+		twentyPercent := int(float64(len(entryPairs)) * 0.2)
+		return entryPairs[:twentyPercent], entryPairs[len(entryPairs)-twentyPercent:]
+	})
+}
+
+//NewEntryPairsQuantileHistogramBoard is like NewEntryPairsHistogramBoard but highlights pairs by duration:
+//pairs at or below the lower percentile are drawn in blue, pairs at or above the upper percentile in red.
+func NewEntryPairsQuantileHistogramBoard(timelines Timelines, lower float64, upper float64) *UniformBoard {
+	return newEntryPairsHistogramBoard(timelines, func(entryPairs EntryPairs) (EntryPairs, EntryPairs) {
+		return entryPairs.FilterByPercentileRange(0, lower), entryPairs.FilterByPercentileRange(upper, 100)
+	})
+}
+
+func newEntryPairsHistogramBoard(timelines Timelines, highlight func(EntryPairs) (EntryPairs, EntryPairs)) *UniformBoard {
 	histograms := NewUniformBoard(len(timelines.Description()), 2, 0.01)
 
 	for i, timelinePoint := range timelines.Description() {
@@ -64,12 +81,12 @@ func NewEntryPairsHistogramBoard(timelines Timelines) *UniformBoard {
 		h.Color = color.RGBA{0, 0, 0, 255}
 		p.Add(h)
 
-		twentyPercent := int(float64(len(entryPairs)) * 0.2)
-		h = NewEntryPairsHistogram(entryPairs[:twentyPercent], 30)
+		low, high := highlight(entryPairs)
+		h = NewEntryPairsHistogram(low, 30)
 		h.Color = color.RGBA{0, 0, 255, 255}
 		p.Add(h)
 
-		h = NewEntryPairsHistogram(entryPairs[len(entryPairs)-twentyPercent:], 30)
+		h = NewEntryPairsHistogram(high, 30)
 		h.Color = color.RGBA{255, 0, 0, 255}
 		p.Add(h)
 
@@ -0,0 +1,338 @@
+package viz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+)
+
+//HTMLReport is a single, self-contained HTML file describing a set of Timelines.
+//
+//The report contains:
+//
+//- an interactive timeline view: hover over a segment to see its annotation, VM and the durations of every segment in the timeline,
+//click on a segment to see the underlying lager entries, scroll to zoom and drag to pan along the time axis.
+//- a table of DTStats for each TimelinePoint
+//- any boards added with AddBoard (embedded as SVG)
+//
+//The report has no external dependencies and can be viewed offline.
+type HTMLReport struct {
+	Title     string
+	Timelines Timelines
+	boards    []htmlReportBoard
+}
+
+type svgWriter interface {
+	WriteSVG(width, height float64, w io.Writer) error
+}
+
+type htmlReportBoard struct {
+	Title string
+	SVG   template.HTML
+}
+
+//NewHTMLReport returns an HTMLReport for the passed-in Timelines that embeds histograms and a correlation matrix
+func NewHTMLReport(title string, timelines Timelines) (*HTMLReport, error) {
+	report := &HTMLReport{
+		Title:     title,
+		Timelines: timelines,
+	}
+
+	err := report.AddBoard("Histograms", NewEntryPairsHistogramBoard(timelines), 3.0*float64(len(timelines.Description())), 6.0)
+	if err != nil {
+		return nil, err
+	}
+
+	correlationBoard, err := NewCorrelationBoard(timelines)
+	if err != nil {
+		return nil, err
+	}
+	err = report.AddBoard("Correlation", correlationBoard, 24.0, 24.0)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+//AddBoard renders the passed-in board (width and height are in inches) and embeds it in the report
+func (r *HTMLReport) AddBoard(title string, board svgWriter, width float64, height float64) error {
+	buffer := &bytes.Buffer{}
+	err := board.WriteSVG(width, height, buffer)
+	if err != nil {
+		return err
+	}
+	r.boards = append(r.boards, htmlReportBoard{
+		Title: title,
+		SVG:   template.HTML(buffer.String()),
+	})
+	return nil
+}
+
+//Save writes the report to the specified file
+func (r *HTMLReport) Save(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	err = r.Write(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//Write writes the report to the passed-in writer
+func (r *HTMLReport) Write(w io.Writer) error {
+	return htmlReportTemplate.Execute(w, map[string]interface{}{
+		"Title":   r.Title,
+		"Boards":  r.boards,
+		"Stats":   r.stats(),
+		"Data":    r.data(),
+		"Columns": []string{"Name", "N", "Min", "Median", "Mean", "P90", "P95", "P99", "Max", "StdDev"},
+	})
+}
+
+type htmlReportPoint struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type htmlReportSegment struct {
+	Point      int     `json:"point"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	VM         string  `json:"vm"`
+	FirstEntry string  `json:"firstEntry"`
+	Entry      string  `json:"entry"`
+}
+
+type htmlReportTimeline struct {
+	Annotation string              `json:"annotation"`
+	Segments   []htmlReportSegment `json:"segments"`
+}
+
+type htmlReportData struct {
+	Points    []htmlReportPoint    `json:"points"`
+	Timelines []htmlReportTimeline `json:"timelines"`
+	Min       float64              `json:"min"`
+	Max       float64              `json:"max"`
+}
+
+func (r *HTMLReport) data() htmlReportData {
+	data := htmlReportData{
+		Min: r.Timelines.StartsAfter().Seconds(),
+		Max: r.Timelines.EndsAfter().Seconds(),
+	}
+
+	for i, point := range r.Timelines.Description() {
+		c := OrderedColors[i%len(OrderedColors)]
+		data.Points = append(data.Points, htmlReportPoint{
+			Name:  point.Name,
+			Color: fmt.Sprintf("rgb(%d,%d,%d)", c.R, c.G, c.B),
+		})
+	}
+
+	for _, timeline := range r.Timelines {
+		htmlTimeline := htmlReportTimeline{
+			Annotation: fmt.Sprintf("%v", timeline.Annotation),
+			Segments:   []htmlReportSegment{},
+		}
+
+		previous := -1
+		for i, entry := range timeline.Entries {
+			if entry.IsZero() {
+				continue
+			}
+			if previous >= 0 {
+				firstEntry := timeline.Entries[previous]
+				htmlTimeline.Segments = append(htmlTimeline.Segments, htmlReportSegment{
+					Point:      i,
+					Start:      firstEntry.Timestamp.Sub(timeline.ZeroEntry.Timestamp).Seconds(),
+					End:        entry.Timestamp.Sub(timeline.ZeroEntry.Timestamp).Seconds(),
+					VM:         entry.VM(),
+					FirstEntry: htmlReportEntryJSON(firstEntry),
+					Entry:      htmlReportEntryJSON(entry),
+				})
+			}
+			previous = i
+		}
+
+		data.Timelines = append(data.Timelines, htmlTimeline)
+	}
+
+	return data
+}
+
+func htmlReportEntryJSON(entry Entry) string {
+	encoded, err := json.MarshalIndent(entry.LagerFormat(), "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(encoded)
+}
+
+func (r *HTMLReport) stats() [][]string {
+	rows := [][]string{}
+	for _, stats := range r.Timelines.DTStatsSlice() {
+		rows = append(rows, []string{
+			stats.Name,
+			fmt.Sprintf("%d", stats.N),
+			stats.Min.String(),
+			stats.Median.String(),
+			stats.Mean.String(),
+			stats.P90.String(),
+			stats.P95.String(),
+			stats.P99.String(),
+			stats.Max.String(),
+			stats.StdDev.String(),
+		})
+	}
+	return rows
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 12px; margin: 20px; }
+h1 { font-size: 20px; }
+h2 { font-size: 16px; margin-top: 30px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 3px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+#timelines { border: 1px solid #ccc; cursor: grab; width: 100%; user-select: none; }
+#timelines rect.segment:hover { stroke: #000; stroke-width: 1; }
+#legend span { display: inline-block; margin-right: 12px; }
+#legend i { display: inline-block; width: 10px; height: 10px; margin-right: 4px; }
+#entry { background: #f5f5f5; padding: 10px; white-space: pre-wrap; min-height: 20px; }
+.board svg { max-width: 100%; height: auto; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+
+<h2>Timelines</h2>
+<div id="legend"></div>
+<p>Scroll to zoom, drag to pan, hover for details, click a segment to see its entries. <button id="reset">Reset zoom</button></p>
+<svg id="timelines"></svg>
+<h2>Entries</h2>
+<div id="entry">Click on a timeline segment.</div>
+
+<h2>Statistics</h2>
+<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Stats}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+
+{{range .Boards}}<h2>{{.Title}}</h2>
+<div class="board">{{.SVG}}</div>
+{{end}}
+
+<script>
+(function() {
+  var data = {{.Data}};
+  var svgNS = "http://www.w3.org/2000/svg";
+  var svg = document.getElementById("timelines");
+  var rowHeight = 10, rowPadding = 2, axisHeight = 20, labelWidth = 0;
+  var viewMin = data.min, viewMax = data.max;
+
+  var legend = document.getElementById("legend");
+  data.points.forEach(function(point) {
+    var span = document.createElement("span");
+    var swatch = document.createElement("i");
+    swatch.style.background = point.color;
+    span.appendChild(swatch);
+    span.appendChild(document.createTextNode(point.name));
+    legend.appendChild(span);
+  });
+
+  function width() { return svg.getBoundingClientRect().width; }
+  function scale(t) { return labelWidth + (t - viewMin) / (viewMax - viewMin) * (width() - labelWidth); }
+  function unscale(x) { return viewMin + (x - labelWidth) / (width() - labelWidth) * (viewMax - viewMin); }
+
+  function node(name, attributes, parent) {
+    var n = document.createElementNS(svgNS, name);
+    for (var key in attributes) { n.setAttribute(key, attributes[key]); }
+    parent.appendChild(n);
+    return n;
+  }
+
+  function details(timeline, segment) {
+    var lines = [timeline.annotation, data.points[segment.point].name + " on " + segment.vm, ""];
+    timeline.segments.forEach(function(s) {
+      lines.push((s === segment ? "> " : "  ") + data.points[s.point].name + ": " + (s.end - s.start).toFixed(3) + "s (" + s.vm + ")");
+    });
+    return lines.join("\n");
+  }
+
+  function render() {
+    while (svg.firstChild) { svg.removeChild(svg.firstChild); }
+    svg.setAttribute("height", axisHeight + data.timelines.length * (rowHeight + rowPadding));
+
+    var ticks = 10;
+    for (var i = 0; i <= ticks; i++) {
+      var t = viewMin + (viewMax - viewMin) * i / ticks;
+      var x = scale(t);
+      node("line", {x1: x, x2: x, y1: axisHeight - 5, y2: "100%", stroke: "#eee"}, svg);
+      var label = node("text", {x: x, y: axisHeight - 8, "font-size": 10, "text-anchor": "middle"}, svg);
+      label.textContent = t.toFixed(2) + "s";
+    }
+
+    data.timelines.forEach(function(timeline, row) {
+      var y = axisHeight + row * (rowHeight + rowPadding);
+      timeline.segments.forEach(function(segment) {
+        if (segment.end < viewMin || segment.start > viewMax) { return; }
+        var left = Math.max(scale(segment.start), labelWidth);
+        var right = Math.min(scale(segment.end), width());
+        var rect = node("rect", {"class": "segment", x: left, y: y, width: Math.max(right - left, 0.5), height: rowHeight, fill: data.points[segment.point].color}, svg);
+        node("title", {}, rect).textContent = details(timeline, segment);
+        rect.addEventListener("click", function() {
+          document.getElementById("entry").textContent = details(timeline, segment) + "\n\nFrom:\n" + segment.firstEntry + "\n\nTo:\n" + segment.entry;
+        });
+      });
+    });
+  }
+
+  svg.addEventListener("wheel", function(e) {
+    e.preventDefault();
+    var t = unscale(e.clientX - svg.getBoundingClientRect().left);
+    var factor = e.deltaY < 0 ? 0.8 : 1.25;
+    viewMin = t - (t - viewMin) * factor;
+    viewMax = t + (viewMax - t) * factor;
+    render();
+  });
+
+  var dragX = null;
+  svg.addEventListener("mousedown", function(e) { dragX = e.clientX; });
+  window.addEventListener("mouseup", function() { dragX = null; });
+  window.addEventListener("mousemove", function(e) {
+    if (dragX === null) { return; }
+    var dt = (dragX - e.clientX) / (width() - labelWidth) * (viewMax - viewMin);
+    viewMin += dt;
+    viewMax += dt;
+    dragX = e.clientX;
+    render();
+  });
+
+  document.getElementById("reset").addEventListener("click", function() {
+    viewMin = data.min;
+    viewMax = data.max;
+    render();
+  });
+  window.addEventListener("resize", render);
+
+  render();
+})();
+</script>
+</body>
+</html>
+`))
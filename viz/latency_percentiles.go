@@ -0,0 +1,72 @@
+package viz
+
+import (
+	"fmt"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/plotter"
+	"github.com/gonum/plot/vg/draw"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+)
+
+//DefaultLatencyPercentiles are the percentiles plotted by NewLatencyPercentilesBoard when none are passed in
+var DefaultLatencyPercentiles = []float64{50, 90, 99}
+
+//NewLatencyPercentilesBoard plots, for each TimelinePoint, the passed-in percentiles of the TimelinePoint's duration
+//in each window (see Timelines.DTStatsByWindow).  The x axis is the time (in seconds) between the start of the first window
+//and the middle of each window.  Windows with no EntryPairs are skipped.
+func NewLatencyPercentilesBoard(timelines Timelines, windows Windows, percentiles []float64) *UniformBoard {
+	if len(percentiles) == 0 {
+		percentiles = DefaultLatencyPercentiles
+	}
+
+	description := timelines.Description()
+	windowed := timelines.DTStatsByWindow(windows)
+	board := NewUniformBoard(1, len(description), 0.02)
+
+	for i, timelinePoint := range description {
+		p, err := plot.New()
+		if err != nil {
+			panic(err)
+		}
+		p.Title.Text = timelinePoint.Name
+		p.Title.Color = OrderedColors[i%len(OrderedColors)]
+		p.Y.Label.Text = "Duration (s)"
+		if i == len(description)-1 {
+			p.X.Label.Text = "Time (s)"
+		}
+
+		for j, percentile := range percentiles {
+			xys := plotter.XYs{}
+			for _, w := range windowed {
+				stats := w.DTStatsSlice[i]
+				if stats.N == 0 {
+					continue
+				}
+				x := w.Window.Start.Sub(windows[0].Start) + w.Window.Duration()/2
+				xys = append(xys, struct{ X, Y float64 }{x.Seconds(), stats.Percentile(percentile).Seconds()})
+			}
+			if len(xys) == 0 {
+				continue
+			}
+
+			line, err := plotter.NewLine(xys)
+			if err != nil {
+				panic(err)
+			}
+			line.LineStyle = draw.LineStyle{
+				Color: OrderedColors[(j+1)%len(OrderedColors)],
+				Width: plotter.DefaultLineStyle.Width,
+			}
+			p.Add(line)
+			p.Legend.Add(fmt.Sprintf("p%g", percentile), line)
+		}
+		p.Legend.Top = true
+		p.Legend.Left = true
+
+		board.AddSubPlotAt(p, 0, len(description)-1-i)
+	}
+
+	return board
+}
@@ -0,0 +1,182 @@
+package viz
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg/draw"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+)
+
+//SessionIciclePlotter plots a session tree as an icicle chart: the root session spans the top row
+//and every nested session is drawn below its parent, spanning the time between its first and last entry.
+//Nested sessions that run concurrently (e.g. parallel work under one parent) are stacked on separate rows.
+//
+//Set Inverted to true to draw the root at the bottom instead.
+//Sessions that logged errors are drawn in red.
+type SessionIciclePlotter struct {
+	Root     *SessionNode
+	Inverted bool
+
+	layout *icicleLayout
+}
+
+func NewSessionIciclePlotter(root *SessionNode, inverted bool) *SessionIciclePlotter {
+	return &SessionIciclePlotter{
+		Root:     root,
+		Inverted: inverted,
+		layout:   newIcicleLayout(root),
+	}
+}
+
+func (s *SessionIciclePlotter) Plot(da draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&da)
+
+	s.Root.Walk(func(node *SessionNode, depth int) error {
+		y := float64(s.layout.Rows - 1 - s.layout.row[node])
+		if s.Inverted {
+			y = float64(s.layout.row[node])
+		}
+
+		left := trX(node.FirstEntry.Timestamp.Sub(s.Root.FirstEntry.Timestamp).Seconds())
+		right := trX(node.LastEntry.Timestamp.Sub(s.Root.FirstEntry.Timestamp).Seconds())
+		if right-left < 1 {
+			right = left + 1
+		}
+		top := trY(y + 0.95)
+		bottom := trY(y + 0.05)
+
+		if len(node.Errors) > 0 {
+			da.SetColor(color.RGBA{255, 0, 0, 255})
+		} else {
+			da.SetColor(icicleColors[depth%len(icicleColors)])
+		}
+		da.Fill(pathRectangle(top, right, bottom, left))
+
+		label := node.Name()
+		if defaultFont.Width(label)+4 < right-left {
+			textStyle := draw.TextStyle{
+				Color: color.Black,
+				Font:  defaultFont,
+			}
+			da.FillText(textStyle, left+2, (top+bottom)/2, 0, -0.5, label)
+		}
+
+		return nil
+	})
+}
+
+func (s *SessionIciclePlotter) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin = 0.0
+	xmax = s.Root.Duration().Seconds()
+	ymin = 0.0
+	ymax = float64(s.layout.Rows)
+
+	return
+}
+
+var icicleColors = []color.RGBA{
+	{255, 200, 100, 255},
+	{255, 170, 80, 255},
+	{240, 210, 120, 255},
+	{250, 150, 60, 255},
+	{230, 190, 90, 255},
+}
+
+//icicleLayout assigns every session in a tree to a row (the root is on row 0).  Each session sits on the row above its nested
+//sessions; nested sessions that overlap in time are packed onto separate tracks, like overlapping segments in a swimlane,
+//and each track is as tall as the tallest subtree on it.
+type icicleLayout struct {
+	Rows int
+
+	row          map[*SessionNode]int
+	track        map[*SessionNode]int
+	trackHeights map[*SessionNode][]int
+}
+
+func newIcicleLayout(root *SessionNode) *icicleLayout {
+	l := &icicleLayout{
+		row:          map[*SessionNode]int{},
+		track:        map[*SessionNode]int{},
+		trackHeights: map[*SessionNode][]int{},
+	}
+	l.Rows = l.measure(root)
+	l.place(root, 0)
+	return l
+}
+
+//measure packs the node's children onto tracks and returns the number of rows the node's subtree occupies
+func (l *icicleLayout) measure(node *SessionNode) int {
+	trackEnds := []time.Time{}
+	heights := []int{}
+	for _, child := range node.Children {
+		height := l.measure(child)
+
+		//children are ordered by the time they first log: greedily pick the first track that is free by then
+		track := 0
+		for track < len(trackEnds) && trackEnds[track].After(child.FirstEntry.Timestamp) {
+			track++
+		}
+		if track == len(trackEnds) {
+			trackEnds = append(trackEnds, child.LastEntry.Timestamp)
+			heights = append(heights, height)
+		} else {
+			trackEnds[track] = child.LastEntry.Timestamp
+			if height > heights[track] {
+				heights[track] = height
+			}
+		}
+		l.track[child] = track
+	}
+	l.trackHeights[node] = heights
+
+	rows := 1
+	for _, height := range heights {
+		rows += height
+	}
+	return rows
+}
+
+func (l *icicleLayout) place(node *SessionNode, row int) {
+	l.row[node] = row
+	offsets := []int{}
+	offset := row + 1
+	for _, height := range l.trackHeights[node] {
+		offsets = append(offsets, offset)
+		offset += height
+	}
+	for _, child := range node.Children {
+		l.place(child, offsets[l.track[child]])
+	}
+}
+
+//NewSessionIcicleBoard plots an icicle chart (inverted: root at the bottom) for each of the passed-in session nodes, stacked vertically
+func NewSessionIcicleBoard(nodes []*SessionNode, inverted bool) *UniformBoard {
+	board := NewUniformBoard(1, len(nodes), 0.02)
+
+	for i, node := range nodes {
+		p, err := plot.New()
+		if err != nil {
+			panic(err)
+		}
+		p.Title.Text = fmt.Sprintf("%s on %s [%s] - %s", node.Name(), node.VM, node.Session, node.Duration())
+		p.X.Label.Text = "Time (s)"
+		p.Y.Tick.Marker = plot.ConstantTicks([]plot.Tick{})
+		p.Add(NewSessionIciclePlotter(node, inverted))
+		board.AddSubPlotAt(p, 0, len(nodes)-1-i)
+	}
+
+	return board
+}
+
+//IcicleHeight returns a reasonable height (in inches) for a board plotting the passed-in session nodes
+func IcicleHeight(nodes []*SessionNode) float64 {
+	height := 0.0
+	for _, node := range nodes {
+		height += 1.0 + 0.25*float64(newIcicleLayout(node).Rows)
+	}
+	return height
+}
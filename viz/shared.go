@@ -19,6 +19,8 @@ func init() {
 	}
 }
 
+//OrderedColors is the palette used for TimelinePoints: the i-th TimelinePoint is drawn with OrderedColors[i%len(OrderedColors)]
+//so that anything computed for a TimelinePoint matches the TimelinePoint's color in the other plots.
 var OrderedColors = []color.RGBA{
 	{0, 0, 0, 255},
 	{255, 0, 0, 255},
@@ -0,0 +1,124 @@
+package viz
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg"
+	"github.com/gonum/plot/vg/draw"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+)
+
+//StackedAreaPlotter plots a TimeSeriesSlice as a stacked area chart: each series is drawn on top of the series that precede it
+type StackedAreaPlotter struct {
+	Series TimeSeriesSlice
+}
+
+func NewStackedAreaPlotter(series TimeSeriesSlice) *StackedAreaPlotter {
+	return &StackedAreaPlotter{
+		Series: series,
+	}
+}
+
+func (s *StackedAreaPlotter) Plot(da draw.Canvas, p *plot.Plot) {
+	if len(s.Series) == 0 {
+		return
+	}
+	trX, trY := p.Transforms(&da)
+
+	times := s.Series[0].Times()
+	step := s.Series[0].Step
+	baseline := make([]float64, len(times))
+
+	for i, series := range s.Series {
+		if series.Max() == 0 {
+			continue
+		}
+
+		path := vg.Path{}
+		for j, t := range times {
+			top := baseline[j] + series.Values[j]
+			if j == 0 {
+				path.Move(trX(t.Seconds()), trY(top))
+			} else {
+				path.Line(trX(t.Seconds()), trY(top))
+			}
+			path.Line(trX((t + step).Seconds()), trY(top))
+		}
+		for j := len(times) - 1; j >= 0; j-- {
+			path.Line(trX((times[j] + step).Seconds()), trY(baseline[j]))
+			path.Line(trX(times[j].Seconds()), trY(baseline[j]))
+		}
+		path.Close()
+
+		da.SetColor(OrderedColors[i%len(OrderedColors)])
+		da.Fill(path)
+
+		for j := range baseline {
+			baseline[j] += series.Values[j]
+		}
+	}
+}
+
+func (s *StackedAreaPlotter) DataRange() (xmin, xmax, ymin, ymax float64) {
+	if len(s.Series) == 0 {
+		return
+	}
+	first := s.Series[0]
+	xmin = first.Start.Seconds()
+	xmax = (first.Start + first.Step*time.Duration(len(first.Values))).Seconds()
+	ymin = 0.0
+	ymax = s.Series.Sum().Max()
+
+	return
+}
+
+type areaThumbnailer struct {
+	Color color.Color
+}
+
+func (a *areaThumbnailer) Thumbnail(c *draw.Canvas) {
+	c.SetColor(a.Color)
+	c.Fill(pathRectangle(c.Max.Y, c.Max.X, c.Min.Y, c.Min.X))
+}
+
+//NewConcurrencyBoard plots the number of Timelines in flight in each TimelinePoint segment (top) and the number of
+//Timelines reaching each TimelinePoint (bottom) over time, as stacked area charts with buckets of the passed-in width.
+func NewConcurrencyBoard(timelines Timelines, step time.Duration) *UniformBoard {
+	board := NewUniformBoard(1, 2, 0.02)
+
+	inFlight, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+	inFlight.Title.Text = "In Flight"
+	inFlight.Y.Label.Text = "Timelines"
+	inFlight.Add(NewStackedAreaPlotter(timelines.InFlight(step)))
+	for i, timelinePoint := range timelines.Description() {
+		if i > 0 {
+			inFlight.Legend.Add(timelinePoint.Name, &areaThumbnailer{OrderedColors[i%len(OrderedColors)]})
+		}
+	}
+	inFlight.Legend.Top = true
+	inFlight.Legend.Left = true
+	board.AddSubPlotAt(inFlight, 0, 1)
+
+	throughput, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+	throughput.Title.Text = "Throughput"
+	throughput.X.Label.Text = "Time (s)"
+	throughput.Y.Label.Text = "Timelines per " + step.String()
+	throughput.Add(NewStackedAreaPlotter(timelines.Throughput(step)))
+	for i, timelinePoint := range timelines.Description() {
+		throughput.Legend.Add(timelinePoint.Name, &areaThumbnailer{OrderedColors[i%len(OrderedColors)]})
+	}
+	throughput.Legend.Top = true
+	throughput.Legend.Left = true
+	board.AddSubPlotAt(throughput, 0, 0)
+
+	return board
+}
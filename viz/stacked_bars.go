@@ -0,0 +1,119 @@
+package viz
+
+import (
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg/draw"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+)
+
+//StackedBarsPlotter plots one vertical bar per label.  Each bar stacks Values[i] from the bottom up.
+type StackedBarsPlotter struct {
+	Labels []string
+	Values [][]float64
+}
+
+func NewStackedBarsPlotter(labels []string, values [][]float64) *StackedBarsPlotter {
+	return &StackedBarsPlotter{
+		Labels: labels,
+		Values: values,
+	}
+}
+
+func (s *StackedBarsPlotter) Plot(da draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&da)
+
+	for i, stack := range s.Values {
+		left, right := trX(float64(i)-0.35), trX(float64(i)+0.35)
+		bottom := 0.0
+		for j, value := range stack {
+			if value <= 0 {
+				continue
+			}
+			da.SetColor(OrderedColors[j%len(OrderedColors)])
+			da.Fill(pathRectangle(trY(bottom+value), right, trY(bottom), left))
+			bottom += value
+		}
+	}
+}
+
+//Ticks returns X-axis ticks that label each bar
+func (s *StackedBarsPlotter) Ticks() []plot.Tick {
+	ticks := []plot.Tick{}
+	for i, label := range s.Labels {
+		ticks = append(ticks, plot.Tick{
+			Value: float64(i),
+			Label: label,
+		})
+	}
+	return ticks
+}
+
+func (s *StackedBarsPlotter) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin = -0.5
+	xmax = float64(len(s.Values)) - 0.5
+	ymin = 0.0
+	for _, stack := range s.Values {
+		total := 0.0
+		for _, value := range stack {
+			if value > 0 {
+				total += value
+			}
+		}
+		if total > ymax {
+			ymax = total
+		}
+	}
+
+	return
+}
+
+//NewCriticalPathBoard plots one stacked bar per summary of the CriticalPathReport (fastest, all and slowest timelines).
+//The top plot stacks the mean share of elapsed time each TimelinePoint accounts for, the bottom plot stacks the mean durations.
+func NewCriticalPathBoard(report CriticalPathReport) *UniformBoard {
+	board := NewUniformBoard(1, 2, 0.02)
+
+	labels := []string{}
+	shares := [][]float64{}
+	durations := [][]float64{}
+	for _, summary := range report.Summaries {
+		labels = append(labels, summary.Name)
+		stackedShares := []float64{}
+		stackedDurations := []float64{}
+		for i := range report.Description {
+			stackedShares = append(stackedShares, summary.MeanShares[i]*100)
+			stackedDurations = append(stackedDurations, summary.MeanDurations[i].Seconds())
+		}
+		shares = append(shares, stackedShares)
+		durations = append(durations, stackedDurations)
+	}
+
+	sharePlot, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+	sharePlot.Title.Text = "Critical Path"
+	sharePlot.Y.Label.Text = "Share of elapsed time (%)"
+	sharePlotter := NewStackedBarsPlotter(labels, shares)
+	sharePlot.X.Tick.Marker = plot.ConstantTicks(sharePlotter.Ticks())
+	sharePlot.Add(sharePlotter)
+	for i, timelinePoint := range report.Description {
+		if i > 0 {
+			sharePlot.Legend.Add(timelinePoint.Name, &areaThumbnailer{OrderedColors[i%len(OrderedColors)]})
+		}
+	}
+	sharePlot.Legend.Top = true
+	board.AddSubPlotAt(sharePlot, 0, 1)
+
+	durationPlot, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+	durationPlot.Y.Label.Text = "Mean duration (s)"
+	durationPlotter := NewStackedBarsPlotter(labels, durations)
+	durationPlot.X.Tick.Marker = plot.ConstantTicks(durationPlotter.Ticks())
+	durationPlot.Add(durationPlotter)
+	board.AddSubPlotAt(durationPlot, 0, 0)
+
+	return board
+}
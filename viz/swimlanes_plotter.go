@@ -0,0 +1,270 @@
+package viz
+
+import (
+	"image/color"
+	"sort"
+
+	"github.com/gonum/plot"
+	"github.com/gonum/plot/vg/draw"
+
+	. "github.com/cloudfoundry-incubator/cicerone/dsl"
+)
+
+//SwimlanesPlotter plots Timelines as a Gantt chart with one swimlane per VM.
+//
+//Every segment of a timeline (the time between two consecutive TimelinePoints) is drawn on the lane
+//of the VM that emitted the segment's closing entry and is colored by that TimelinePoint.  Segments that
+//overlap within a lane are stacked on separate rows and the lane's background is shaded in proportion
+//to the number of segments running concurrently on the VM.
+type SwimlanesPlotter struct {
+	Timelines  Timelines
+	MinSeconds float64
+	MaxSeconds float64
+	Padding    float64
+
+	lanes []swimlane
+}
+
+type swimlane struct {
+	VM       string
+	Segments []swimlaneSegment
+	Rows     int
+	Y        float64
+}
+
+type swimlaneSegment struct {
+	Left  float64
+	Right float64
+	Row   int
+	Color color.Color
+}
+
+func NewSwimlanesPlotter(timelines Timelines, minSeconds float64, maxSeconds float64) *SwimlanesPlotter {
+	s := &SwimlanesPlotter{
+		Timelines:  timelines,
+		MinSeconds: minSeconds,
+		MaxSeconds: maxSeconds,
+		Padding:    0.5,
+	}
+	s.layout()
+	return s
+}
+
+func (s *SwimlanesPlotter) layout() {
+	segmentsByVM := map[string][]swimlaneSegment{}
+	for _, timeline := range s.Timelines {
+		previous := -1
+		for i, entry := range timeline.Entries {
+			if entry.IsZero() {
+				continue
+			}
+			if previous >= 0 {
+				segmentsByVM[entry.VM()] = append(segmentsByVM[entry.VM()], swimlaneSegment{
+					Left:  timeline.Entries[previous].Timestamp.Sub(timeline.ZeroEntry.Timestamp).Seconds(),
+					Right: entry.Timestamp.Sub(timeline.ZeroEntry.Timestamp).Seconds(),
+					Color: OrderedColors[i%len(OrderedColors)],
+				})
+			}
+			previous = i
+		}
+	}
+
+	vms := []string{}
+	for vm := range segmentsByVM {
+		vms = append(vms, vm)
+	}
+	sort.Strings(vms)
+
+	s.lanes = []swimlane{}
+	y := s.Padding
+	for _, vm := range vms {
+		segments := segmentsByVM[vm]
+		sort.Stable(bySegmentStart(segments))
+
+		//greedily pack each segment onto the first row that is free by the time it starts
+		rowEnds := []float64{}
+		for i := range segments {
+			row := 0
+			for row < len(rowEnds) && rowEnds[row] > segments[i].Left {
+				row++
+			}
+			if row == len(rowEnds) {
+				rowEnds = append(rowEnds, segments[i].Right)
+			} else {
+				rowEnds[row] = segments[i].Right
+			}
+			segments[i].Row = row
+		}
+
+		s.lanes = append(s.lanes, swimlane{
+			VM:       vm,
+			Segments: segments,
+			Rows:     len(rowEnds),
+			Y:        y,
+		})
+		y += float64(len(rowEnds)) + s.Padding
+	}
+}
+
+func (s *SwimlanesPlotter) Plot(da draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&da)
+
+	maxConcurrency := 1
+	for _, lane := range s.lanes {
+		if lane.Rows > maxConcurrency {
+			maxConcurrency = lane.Rows
+		}
+	}
+
+	for _, lane := range s.lanes {
+		top := trY(lane.Y + float64(lane.Rows))
+		bottom := trY(lane.Y)
+
+		for _, interval := range lane.concurrency() {
+			shade := uint8(235 - 135*float64(interval.Count-1)/float64(maxConcurrency))
+			da.SetColor(color.RGBA{shade, shade, shade, 255})
+			da.Fill(pathRectangle(top, trX(interval.Right), bottom, trX(interval.Left)))
+		}
+
+		for _, segment := range lane.Segments {
+			left := trX(segment.Left)
+			right := trX(segment.Right)
+			if right-left < 1 {
+				right = left + 1
+			}
+			y := lane.Y + float64(segment.Row)
+			da.SetColor(segment.Color)
+			da.Fill(pathRectangle(trY(y+0.9), right, trY(y+0.1), left))
+		}
+	}
+
+	y := s.lanesHeight()
+	description := s.Timelines.Description()
+	dx := (s.MaxSeconds - s.MinSeconds) / float64(len(description)+1)
+
+	x := s.MinSeconds + dx
+	for i := 1; i < len(description); i++ {
+		da.SetColor(OrderedColors[i%len(OrderedColors)])
+		da.Fill(pathRectangle(trY(y+s.legendHeight()*0.5), trX(x+dx), trY(y+s.legendHeight()*0.1), trX(x)))
+		x += dx
+	}
+
+	x = s.MinSeconds + dx
+	for i := 0; i < len(description); i++ {
+		textStyle := draw.TextStyle{
+			Color: OrderedColors[i%len(OrderedColors)],
+			Font:  defaultFont,
+		}
+
+		da.FillText(textStyle, trX(x), trY(y+s.legendHeight()*0.6), -0.5, 0, description[i].Name)
+		x += dx
+	}
+}
+
+type concurrencyInterval struct {
+	Left  float64
+	Right float64
+	Count int
+}
+
+//concurrency returns the intervals during which at least one segment is running on the lane, with the number of segments running
+func (l swimlane) concurrency() []concurrencyInterval {
+	boundaries := concurrencyBoundaries{}
+	for _, segment := range l.Segments {
+		boundaries = append(boundaries, concurrencyBoundary{segment.Left, 1}, concurrencyBoundary{segment.Right, -1})
+	}
+	sort.Sort(boundaries)
+
+	intervals := []concurrencyInterval{}
+	count := 0
+	for i, b := range boundaries {
+		count += b.Delta
+		if count > 0 && i+1 < len(boundaries) && boundaries[i+1].X > b.X {
+			intervals = append(intervals, concurrencyInterval{b.X, boundaries[i+1].X, count})
+		}
+	}
+	return intervals
+}
+
+func (s *SwimlanesPlotter) lanesHeight() float64 {
+	if len(s.lanes) == 0 {
+		return s.Padding
+	}
+	last := s.lanes[len(s.lanes)-1]
+	return last.Y + float64(last.Rows) + s.Padding
+}
+
+func (s *SwimlanesPlotter) legendHeight() float64 {
+	return s.lanesHeight() * 0.05
+}
+
+//Ticks returns Y-axis ticks that label each lane with its VM
+func (s *SwimlanesPlotter) Ticks() []plot.Tick {
+	ticks := []plot.Tick{}
+	for _, lane := range s.lanes {
+		ticks = append(ticks, plot.Tick{
+			Value: lane.Y + float64(lane.Rows)/2.0,
+			Label: lane.VM,
+		})
+	}
+	return ticks
+}
+
+//Height returns a reasonable height (in inches) for a plot of the swimlanes
+func (s *SwimlanesPlotter) Height() float64 {
+	height := 2.0 + 0.15*s.lanesHeight()
+	if height < 4.0 {
+		return 4.0
+	}
+	return height
+}
+
+func (s *SwimlanesPlotter) DataRange() (xmin, xmax, ymin, ymax float64) {
+	ymin = 0.0
+	ymax = s.lanesHeight() + s.legendHeight()
+	xmin = s.MinSeconds
+	xmax = s.MaxSeconds
+
+	return
+}
+
+type concurrencyBoundary struct {
+	X     float64
+	Delta int
+}
+
+//concurrencyBoundaries sort by time, with segments ending before segments starting at the same time
+type concurrencyBoundaries []concurrencyBoundary
+
+func (b concurrencyBoundaries) Len() int      { return len(b) }
+func (b concurrencyBoundaries) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b concurrencyBoundaries) Less(i, j int) bool {
+	if b[i].X == b[j].X {
+		return b[i].Delta < b[j].Delta
+	}
+	return b[i].X < b[j].X
+}
+
+type bySegmentStart []swimlaneSegment
+
+func (s bySegmentStart) Len() int           { return len(s) }
+func (s bySegmentStart) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s bySegmentStart) Less(i, j int) bool { return s[i].Left < s[j].Left }
+
+//NewSwimlanesBoard plots the passed-in Timelines as per-VM swimlanes.  It returns the board and a reasonable height (in inches) to save it at.
+func NewSwimlanesBoard(title string, timelines Timelines) (*Board, float64) {
+	plotter := NewSwimlanesPlotter(timelines, timelines.StartsAfter().Seconds(), timelines.EndsAfter().Seconds())
+
+	p, err := plot.New()
+	if err != nil {
+		panic(err)
+	}
+	p.Title.Text = title
+	p.X.Label.Text = "Time (s)"
+	p.Y.Tick.Marker = plot.ConstantTicks(plotter.Ticks())
+	p.Add(plotter)
+
+	board := &Board{}
+	board.AddSubPlot(p, Rect{0, 0, 1.0, 1.0})
+	return board, plotter.Height()
+}